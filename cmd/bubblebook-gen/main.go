@@ -0,0 +1,379 @@
+// Command bubblebook-gen writes the bubblebook.Register calls for the
+// stories it finds in a set of packages, so that a book does not have to
+// list them by hand. It is meant to be run by go generate from the book's
+// main package:
+//
+//	//go:generate go run github.com/sarkarshuvojit/bubblebook/cmd/bubblebook-gen ./...
+//
+// Two conventions are recognised:
+//
+//   - Exported functions named StoryXxx that take no arguments and return a
+//     tea.Model. StoryButton_Primary in package widgets is registered as
+//     "widgets/Button/Primary".
+//   - An exported Stories variable, usually kept in a *_stories.go file,
+//     holding a map literal from story names to functions that return a
+//     tea.Model. The entry "Primary" in package widgets is registered as
+//     "widgets/Primary".
+//
+// Stories of the main package are registered without the package name.
+//
+// The generated file registers the stories from an init function, in the
+// order of the packages' import paths and then of the source.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	bubblebookPath = "github.com/sarkarshuvojit/bubblebook/pkg/bubblebook"
+	bubbleteaPath  = "github.com/charmbracelet/bubbletea"
+	// generatedHeader starts every file the generator writes
+	generatedHeader = "// Code generated by bubblebook-gen; DO NOT EDIT.\n"
+)
+
+// listedPackage is the part of `go list -json` output the generator needs.
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	GoFiles    []string
+}
+
+// story is one registration in the generated file.
+type story struct {
+	name string
+	// expr is the expression for the factory, without the package qualifier
+	expr string
+}
+
+// storyPackage is a package that has stories.
+type storyPackage struct {
+	listedPackage
+	stories []story
+}
+
+func main() {
+	output := flag.String("o", "stories_gen.go", "file to write, relative to the current directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: bubblebook-gen [-o file] [packages]\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Writes the bubblebook.Register calls for the stories in the packages\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "(./... by default) into a file of the package in the current directory.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	if err := run(patterns, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "bubblebook-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run scans the packages and writes the registrations for their stories.
+func run(patterns []string, output string) error {
+	targets, err := listPackages(".")
+	if err != nil {
+		return err
+	}
+	if len(targets) != 1 {
+		return errors.New("the current directory must hold exactly one package")
+	}
+	target := targets[0]
+
+	pkgs, err := listPackages(patterns...)
+	if err != nil {
+		return err
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ImportPath < pkgs[j].ImportPath
+	})
+
+	outputPath, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	var found []storyPackage
+	total := 0
+	for _, pkg := range pkgs {
+		stories, err := findStories(pkg, outputPath)
+		if err != nil {
+			return err
+		}
+		if len(stories) == 0 {
+			continue
+		}
+		if pkg.Name == "main" && pkg.ImportPath != target.ImportPath {
+			return fmt.Errorf("%s has stories but is a main package, which cannot be imported", pkg.ImportPath)
+		}
+		found = append(found, storyPackage{listedPackage: pkg, stories: stories})
+		total += len(stories)
+	}
+
+	if total == 0 {
+		// A file registering nothing would import bubblebook without using
+		// it, so none is written, and one left by an earlier run is removed
+		if err := removeGenerated(output); err != nil {
+			return err
+		}
+		fmt.Printf("bubblebook-gen: no stories found, %s not written\n", output)
+		return nil
+	}
+
+	src, err := generate(target, found)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("bubblebook-gen: wrote %d stories from %d packages to %s\n", total, len(found), output)
+	return nil
+}
+
+// removeGenerated deletes a file written by an earlier run. Files the
+// generator did not write are left alone.
+func removeGenerated(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, []byte(generatedHeader)) {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// listPackages runs `go list` on the patterns.
+func listPackages(patterns ...string) ([]listedPackage, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list", "-json"}, patterns...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if out := strings.TrimSpace(stderr.String()); out != "" {
+			return nil, fmt.Errorf("go list: %s", out)
+		}
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	var pkgs []listedPackage
+	dec := json.NewDecoder(&stdout)
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading go list output: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// findStories parses the Go files of a package and returns its stories in
+// source order. The file being generated is skipped.
+func findStories(pkg listedPackage, outputPath string) ([]story, error) {
+	fset := token.NewFileSet()
+	var stories []story
+	for _, name := range pkg.GoFiles {
+		filename := filepath.Join(pkg.Dir, name)
+		if filename == outputPath {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filename, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		tea := bubbleteaName(file)
+		if tea == "" {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if s, ok := storyFunc(pkg.Name, decl, tea); ok {
+					stories = append(stories, s)
+				}
+			case *ast.GenDecl:
+				found, err := storiesVar(pkg.Name, decl, tea)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fset.Position(decl.Pos()), err)
+				}
+				stories = append(stories, found...)
+			}
+		}
+	}
+	return stories, nil
+}
+
+// bubbleteaName returns the name a file imports Bubble Tea under, or "" when
+// it does not import it.
+func bubbleteaName(file *ast.File) string {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != bubbleteaPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return "tea"
+	}
+	return ""
+}
+
+// storyFunc recognises `func StoryXxx() tea.Model`.
+func storyFunc(pkgName string, decl *ast.FuncDecl, tea string) (story, bool) {
+	name, ok := strings.CutPrefix(decl.Name.Name, "Story")
+	if !ok || name == "" || !ast.IsExported(name) || decl.Recv != nil || decl.Type.TypeParams != nil {
+		return story{}, false
+	}
+	if !isFactory(decl.Type, tea) {
+		return story{}, false
+	}
+	return story{
+		name: groupPrefix(pkgName) + strings.ReplaceAll(name, "_", "/"),
+		expr: decl.Name.Name,
+	}, true
+}
+
+// storiesVar recognises `var Stories = map[string]func() tea.Model{...}`
+// with string literal keys.
+func storiesVar(pkgName string, decl *ast.GenDecl, tea string) ([]story, error) {
+	if decl.Tok != token.VAR {
+		return nil, nil
+	}
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		for i, ident := range spec.Names {
+			if ident.Name != "Stories" || i >= len(spec.Values) {
+				continue
+			}
+
+			lit, ok := spec.Values[i].(*ast.CompositeLit)
+			if !ok {
+				return nil, errors.New("Stories must be assigned a map literal")
+			}
+			mapType, ok := lit.Type.(*ast.MapType)
+			if !ok {
+				return nil, errors.New("Stories must be a map from names to story functions")
+			}
+			if fn, ok := mapType.Value.(*ast.FuncType); !ok || !isFactory(fn, tea) {
+				return nil, fmt.Errorf("the values of Stories must have type func() %s.Model", tea)
+			}
+
+			var stories []story
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := kv.Key.(*ast.BasicLit)
+				if !ok || key.Kind != token.STRING {
+					return nil, errors.New("the keys of Stories must be string literals")
+				}
+				name, err := strconv.Unquote(key.Value)
+				if err != nil {
+					return nil, err
+				}
+				stories = append(stories, story{
+					name: groupPrefix(pkgName) + name,
+					expr: "Stories[" + strconv.Quote(name) + "]",
+				})
+			}
+			return stories, nil
+		}
+	}
+	return nil, nil
+}
+
+// groupPrefix returns the start of the names of a package's stories. Stories
+// of the main package are not put in a group of their own.
+func groupPrefix(pkgName string) string {
+	if pkgName == "main" {
+		return ""
+	}
+	return pkgName + "/"
+}
+
+// isFactory reports whether a function type takes nothing and returns a
+// tea.Model.
+func isFactory(fn *ast.FuncType, tea string) bool {
+	if fn.Params.NumFields() != 0 || fn.Results.NumFields() != 1 {
+		return false
+	}
+	sel, ok := fn.Results.List[0].Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Model" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == tea
+}
+
+// generate returns the source of the registration file.
+func generate(target listedPackage, pkgs []storyPackage) ([]byte, error) {
+	var imports, body bytes.Buffer
+	used := map[string]bool{"bubblebook": true}
+
+	for _, pkg := range pkgs {
+		qualifier := ""
+		if pkg.ImportPath != target.ImportPath {
+			// Pick a name that no other import uses
+			name := pkg.Name
+			for i := 2; used[name]; i++ {
+				name = pkg.Name + strconv.Itoa(i)
+			}
+			used[name] = true
+
+			if name == path.Base(pkg.ImportPath) {
+				fmt.Fprintf(&imports, "\t%q\n", pkg.ImportPath)
+			} else {
+				fmt.Fprintf(&imports, "\t%s %q\n", name, pkg.ImportPath)
+			}
+			qualifier = name + "."
+		}
+
+		for _, s := range pkg.stories {
+			fmt.Fprintf(&body, "\tbubblebook.Register(%q, %s%s)\n", s.name, qualifier, s.expr)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%s\n", generatedHeader)
+	fmt.Fprintf(&src, "package %s\n\n", target.Name)
+	fmt.Fprintf(&src, "import (\n\t%q\n\n%s)\n\n", bubblebookPath, imports.String())
+	fmt.Fprintf(&src, "func init() {\n%s}\n", body.String())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files with the given contents in dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// packageIn describes the Go files of a package in dir.
+func packageIn(dir, name, importPath string, files ...string) listedPackage {
+	return listedPackage{Dir: dir, ImportPath: importPath, Name: name, GoFiles: files}
+}
+
+func TestFindStories(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"button.go": `package widgets
+
+import tea "github.com/charmbracelet/bubbletea"
+
+func StoryButton_Primary() tea.Model { return nil }
+
+// Not stories: arguments, unexported names, methods and other results
+func StoryWithArgs(label string) tea.Model { return nil }
+func Storyteller() tea.Model               { return nil }
+func (b Button) StoryMethod() tea.Model    { return nil }
+func StoryString() string                  { return "" }
+
+type Button struct{}
+`,
+		"input_stories.go": `package widgets
+
+import bt "github.com/charmbracelet/bubbletea"
+
+var Stories = map[string]func() bt.Model{
+	"Input/Empty":  func() bt.Model { return nil },
+	"Input/Filled": func() bt.Model { return nil },
+}
+`,
+		"plain.go": `package widgets
+
+// Files that do not import Bubble Tea are skipped
+func StoryPlain() Model { return nil }
+
+type Model interface{}
+`,
+	})
+
+	pkg := packageIn(dir, "widgets", "example.com/book/widgets", "button.go", "input_stories.go", "plain.go")
+	stories, err := findStories(pkg, filepath.Join(dir, "stories_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []story{
+		{name: "widgets/Button/Primary", expr: "StoryButton_Primary"},
+		{name: "widgets/Input/Empty", expr: `Stories["Input/Empty"]`},
+		{name: "widgets/Input/Filled", expr: `Stories["Input/Filled"]`},
+	}
+	if !reflect.DeepEqual(stories, want) {
+		t.Errorf("findStories() = %+v, want %+v", stories, want)
+	}
+}
+
+func TestFindStoriesSkipsOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"stories_gen.go": `package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+func StoryGenerated() tea.Model { return nil }
+`,
+	})
+
+	pkg := packageIn(dir, "main", "example.com/book", "stories_gen.go")
+	stories, err := findStories(pkg, filepath.Join(dir, "stories_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 0 {
+		t.Errorf("findStories() = %+v, want the output file skipped", stories)
+	}
+}
+
+func TestFindStoriesRejectsDynamicStories(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"stories.go": `package widgets
+
+import tea "github.com/charmbracelet/bubbletea"
+
+var Stories = makeStories()
+
+func makeStories() map[string]func() tea.Model { return nil }
+`,
+	})
+
+	pkg := packageIn(dir, "widgets", "example.com/book/widgets", "stories.go")
+	if _, err := findStories(pkg, ""); err == nil {
+		t.Error("findStories() accepted Stories that is not a map literal")
+	}
+}
+
+func TestGroupNames(t *testing.T) {
+	tests := []struct {
+		pkgName string
+		src     string
+		want    string
+	}{
+		{"widgets", "func StoryButton() tea.Model { return nil }", "widgets/Button"},
+		{"widgets", "func StoryButton_Primary_Large() tea.Model { return nil }", "widgets/Button/Primary/Large"},
+		{"main", "func StoryButton_Primary() tea.Model { return nil }", "Button/Primary"},
+		{"main", `var Stories = map[string]func() tea.Model{"Form/Empty": nil}`, "Form/Empty"},
+		{"forms", `var Stories = map[string]func() tea.Model{"Empty": nil}`, "forms/Empty"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"story.go": "package " + tt.pkgName + "\n\nimport tea \"github.com/charmbracelet/bubbletea\"\n\n" + tt.src + "\n",
+		})
+
+		stories, err := findStories(packageIn(dir, tt.pkgName, "example.com/book", "story.go"), "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.src, err)
+		}
+		if len(stories) != 1 || stories[0].name != tt.want {
+			t.Errorf("package %s, %s: got %+v, want the name %q", tt.pkgName, tt.src, stories, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	target := listedPackage{ImportPath: "example.com/book", Name: "main"}
+	pkgs := []storyPackage{
+		{
+			listedPackage: target,
+			stories:       []story{{name: "Home", expr: "StoryHome"}},
+		},
+		{
+			listedPackage: listedPackage{ImportPath: "example.com/book/widgets", Name: "widgets"},
+			stories:       []story{{name: "widgets/Button", expr: "StoryButton"}},
+		},
+		{
+			listedPackage: listedPackage{ImportPath: "example.com/other/widgets", Name: "widgets"},
+			stories:       []story{{name: "widgets/Input/Empty", expr: `Stories["Input/Empty"]`}},
+		},
+	}
+
+	src, err := generate(target, pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(src)
+	for _, want := range []string{
+		generatedHeader,
+		"package main",
+		`"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook"`,
+		`"example.com/book/widgets"`,
+		`widgets2 "example.com/other/widgets"`,
+		`bubblebook.Register("Home", StoryHome)`,
+		`bubblebook.Register("widgets/Button", widgets.StoryButton)`,
+		`bubblebook.Register("widgets/Input/Empty", widgets2.Stories["Input/Empty"])`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated source lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+// book creates a module in a temporary directory and makes it the working
+// directory.
+func book(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/book\n\ngo 1.24\n"})
+	writeFiles(t, dir, files)
+	t.Chdir(dir)
+	return dir
+}
+
+func TestRunWithoutStories(t *testing.T) {
+	dir := book(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	if err := run([]string{"./..."}, "stories_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stories_gen.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a file was written without stories to register (stat: %v)", err)
+	}
+}
+
+func TestRunRemovesStaleOutput(t *testing.T) {
+	dir := book(t, map[string]string{
+		"main.go":        "package main\n\nfunc main() {}\n",
+		"stories_gen.go": generatedHeader + "\npackage main\n",
+		"handwritten.go": "package main\n",
+	})
+
+	if err := run([]string{"./..."}, "stories_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stories_gen.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file of an earlier run was kept (stat: %v)", err)
+	}
+
+	// Files the generator did not write are never removed
+	if err := run([]string{"./..."}, "handwritten.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "handwritten.go")); err != nil {
+		t.Errorf("a hand-written file was removed: %v", err)
+	}
+}
@@ -14,7 +14,10 @@ func main() {
 
 	bubblebook.Register("Progress Bar", func() tea.Model {
 		return models.NewProgressModel()
-	})
+	},
+		bubblebook.WithPreset("Finish download", models.ProgressDoneMsg{}),
+		bubblebook.WithPreset("Reset", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}),
+	)
 
 	bubblebook.Register("Text Input", func() tea.Model {
 		return models.NewTextInputModel()
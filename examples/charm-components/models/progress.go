@@ -8,6 +8,9 @@ import (
 	"github.com/charmbracelet/bubbles/progress"
 )
 
+// ProgressDoneMsg completes the progress bar
+type ProgressDoneMsg struct{}
+
 type ProgressModel struct {
 	progress progress.Model
 	percent  float64
@@ -26,6 +29,8 @@ func (m ProgressModel) Init() tea.Cmd {
 
 func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case ProgressDoneMsg:
+		m.percent = 1.0
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "+", "right", "l":
@@ -25,6 +25,16 @@ func (m TextInputModel) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+// Focus and Blur have pointer receivers, like textinput.Model's own. The
+// book calls them when the preview gains or loses the focus.
+func (m *TextInputModel) Focus() tea.Cmd {
+	return m.textInput.Focus()
+}
+
+func (m *TextInputModel) Blur() {
+	m.textInput.Blur()
+}
+
 func (m TextInputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
@@ -0,0 +1,55 @@
+package bubblebook
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/contrast"
+)
+
+// WithPreviewBackground sets the background assumed behind stories that do
+// not set their own when auditing contrast, such as lipgloss.Color("#ffffff")
+// for books meant for light terminals. The default is a dark grey.
+func WithPreviewBackground(background color.Color) Option {
+	return func(o *options) {
+		o.model.PreviewBackground = background
+	}
+}
+
+// AuditContrast renders a registered component at the given size and
+// returns the runs of text whose WCAG contrast ratio is below
+// opts.MinRatio. Zero options fall back to contrast.DefaultOptions.
+func AuditContrast(name string, width, height int, opts contrast.Options) ([]contrast.Region, error) {
+	entry, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := renderEntry(entry, width, height)
+	if err != nil {
+		return nil, err
+	}
+	return contrast.Audit(view, width, height, opts), nil
+}
+
+// parseColor parses a colour written as #rrggbb or as an ANSI colour number.
+func parseColor(s string) (color.Color, error) {
+	if len(s) == 7 && strings.HasPrefix(s, "#") {
+		if v, err := strconv.ParseUint(s[1:], 16, 32); err == nil {
+			return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return ansi.IndexedColor(n), nil
+	}
+	return nil, fmt.Errorf("invalid colour %q, expected #rrggbb or a number from 0 to 255", s)
+}
+
+// hexColor formats a colour as #rrggbb.
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
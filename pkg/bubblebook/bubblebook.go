@@ -1,10 +1,14 @@
 package bubblebook
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/console"
 	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
 )
 
@@ -13,18 +17,148 @@ type ComponentFactory func() tea.Model
 
 var components []models.ComponentEntry
 
+// Option configures the Bubblebook TUI launched by Start.
+type Option func(*options)
+
+// options holds the settings collected from Option values.
+type options struct {
+	model models.Config
+	ssh   sshOptions
+	web   webOptions
+}
+
+// WithSlowThreshold sets the p95 Update or View duration above which a
+// component is marked as slow in the sidebar. Zero disables the warning.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		o.model.SlowThreshold = threshold
+	}
+}
+
+// WithHistoryLimit sets how many states of the active component are kept
+// for time travel. A limit of zero disables time travel.
+func WithHistoryLimit(limit int) Option {
+	return func(o *options) {
+		o.model.HistoryLimit = limit
+	}
+}
+
+// WithIsolateKey sets the chord that leaves isolated mode, written the way
+// Bubble Tea names keys, such as "ctrl+]" (the default) or "ctrl+x".
+func WithIsolateKey(key string) Option {
+	return func(o *options) {
+		o.model.IsolateKey = key
+	}
+}
+
+// StoryOption configures a story passed to Register.
+type StoryOption func(*models.ComponentEntry)
+
+// WithPreset adds a named list of messages to a story, such as
+// "Simulate network error" sending errMsg{...}. Presets are listed in a
+// panel and bound to the keys 1 to 9, in the order they are added, so that
+// states that are hard to reach can be set up with one key.
+func WithPreset(label string, msgs ...tea.Msg) StoryOption {
+	return func(e *models.ComponentEntry) {
+		e.Presets = append(e.Presets, models.Preset{Label: label, Msgs: msgs})
+	}
+}
+
+// WithConsoleMsg adds a command to the message console that sends a custom
+// message to this story only, such as "error timeout" sending errMsg{...}.
+// Typing "name args" while the story is active calls parse with the args
+// and sends the message it returns. Unlike RegisterConsoleMsg, stories can
+// use the same name for commands of their own. It panics when name is
+// empty, contains spaces or is one of the built-in commands.
+func WithConsoleMsg(name string, parse func(args string) (tea.Msg, error)) StoryOption {
+	if err := console.CheckName(name); err != nil {
+		panic(fmt.Sprintf("bubblebook: %v", err))
+	}
+	return func(e *models.ComponentEntry) {
+		if e.ConsoleMsgs == nil {
+			e.ConsoleMsgs = make(map[string]console.ParseFunc)
+		}
+		e.ConsoleMsgs[name] = parse
+	}
+}
+
 // Register adds a component to the Bubblebook registry.
-func Register(name string, factory ComponentFactory) {
-	components = append(components, models.ComponentEntry{
+func Register(name string, factory ComponentFactory, opts ...StoryOption) {
+	entry := models.ComponentEntry{
 		Name:    name,
 		Factory: factory,
-	})
+	}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+	components = append(components, entry)
 }
 
-// Start launches the Bubblebook TUI.
-func Start() {
+// Start launches the Bubblebook TUI, or runs one of the book's commands
+// when the program is started with one, such as `list`, `render <story>` or
+// `watch`. Run the book with --help to see them all.
+//
+// The UI state, such as the selected story, is saved when the TUI exits and
+// restored the next time it starts in the same directory, unless it is run
+// with --no-state. --clear-state discards the saved state. Other flags are
+// ignored, so books can parse flags of their own before calling Start, but
+// a first argument that is neither a flag nor a command is an error.
+func Start(opts ...Option) {
+	o := options{model: models.DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := runCLI(os.Args[1:], o); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		var usage usageError
+		if errors.As(err, &usage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// isolateEnv names a story to run alone, without the book around it.
+const isolateEnv = "BUBBLEBOOK_ISOLATE"
+
+// runIsolated runs a story as the root model of the program, exactly as it
+// would run in an app. The isolate key quits.
+func runIsolated(name string, o options) error {
+	entry, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	program := tea.NewProgram(
+		models.NewIsolatedModel(entry.Factory(), o.model.IsolateKey),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err = program.Run()
+	return err
+}
+
+// runTUI opens the TUI, on the given story when it is not empty.
+func runTUI(flags startFlags, o options, story string) error {
 	// Create the main model
-	model := models.NewBubblebookModel(components)
+	model := models.NewBubblebookModelWithConfig(components, o.model)
+
+	// Pick up where the previous run left off
+	statePaths := sessionPaths(flags)
+	for _, path := range statePaths {
+		if state, err := loadSession(path); err == nil {
+			model.RestoreSession(state)
+			break
+		}
+	}
+	if story != "" {
+		model.ShowStory(story)
+	}
 
 	// Create the program
 	program := tea.NewProgram(
@@ -34,8 +168,18 @@ func Start() {
 	)
 
 	// Run the program
-	if _, err := program.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running bubblebook: %v\n", err)
-		os.Exit(1)
+	final, err := program.Run()
+	if err != nil {
+		return err
+	}
+
+	// Keep the UI state for the next run
+	if m, ok := final.(models.BubblebookModel); ok {
+		for _, path := range statePaths {
+			if err := saveSession(path, m.SessionState()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving bubblebook state: %v\n", err)
+			}
+		}
 	}
+	return nil
 }
@@ -0,0 +1,523 @@
+package bubblebook
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/contrast"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/headless"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/lint"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// command is a subcommand of a book's command line.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string, o options) error
+}
+
+// commands lists the subcommands in the order the help shows them.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"list", "", "Print the registered stories with their groups and presets", runList},
+		{"show", "<story> [--isolated]", "Open the TUI on one story, or run it alone", runShow},
+		{"render", "<story> [--size WxH] [--plain]", "Print a snapshot of one story", runRender},
+		{"check", "[--size WxH] [--strict]", "Render every story headlessly and report failures and layout mistakes", runCheck},
+		{"sweep", "<story> [--width min-max] [--height min-max] [--step WxH]", "Render one story across a range of sizes and report where its layout breaks", runSweep},
+		{"contrast", "[story...] [--size WxH] [--min ratio] [--background color]", "Report text with too little contrast", runContrast},
+		{"export-html", "[dir] [--size WxH]", "Write a static HTML catalogue", runExportHTML},
+		{"watch", "[package] [--interval d] [--no-state] [--clear-state]", "Rebuild and restart the book when its source changes", runWatch},
+		{"serve", "[--http addr] [--allow-remote] [--token token] [--ssh addr] [--host-key file]", "Serve the book to browsers or SSH clients", runServe},
+		{"help", "", "Show this help", runHelp},
+	}
+}
+
+// usageError is returned for a malformed command line.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// runCLI runs the subcommand named by the first argument, or the TUI when
+// the arguments start with a flag or there are none. Flags that are not
+// the TUI's are left to the book, which may parse flags of its own before
+// calling Start.
+func runCLI(args []string, o options) error {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		return runHelp(nil, o)
+	}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd.run(args[1:], o)
+			}
+		}
+		return usageError{fmt.Errorf("unknown command %q", args[0])}
+	}
+
+	flags := scanStartFlags(args)
+	if story := os.Getenv(isolateEnv); story != "" {
+		return runIsolated(story, o)
+	}
+	return runTUI(flags, o, "")
+}
+
+// runHelp handles `help` and `--help`.
+func runHelp(args []string, o options) error {
+	printUsage(os.Stdout)
+	return nil
+}
+
+// printUsage writes the list of commands.
+func printUsage(w io.Writer) {
+	prog := filepath.Base(os.Args[0])
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\n", prog)
+	fmt.Fprintf(w, "Without a command, the TUI is opened. Flags:\n")
+	fmt.Fprintf(w, "  --no-state     neither restore nor save the UI state\n")
+	fmt.Fprintf(w, "  --clear-state  delete the saved UI state before starting\n")
+	fmt.Fprintf(w, "Other flags are left to the book.\n")
+	fmt.Fprintf(w, "Set %s to a story name to run that story alone instead.\n\n", isolateEnv)
+	fmt.Fprintf(w, "Commands:\n")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	tw.Flush()
+}
+
+// startFlags are the flags accepted when the TUI is launched.
+type startFlags struct {
+	noState    bool
+	clearState bool
+	// isolated runs the story given to show without the book around it
+	isolated bool
+}
+
+// scanStartFlags picks --no-state and --clear-state out of the arguments
+// the TUI is started with, ignoring any others.
+func scanStartFlags(args []string) startFlags {
+	var f startFlags
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		on := true
+		if hasValue {
+			var err error
+			if on, err = strconv.ParseBool(value); err != nil {
+				continue
+			}
+		}
+		switch name {
+		case "no-state":
+			f.noState = on
+		case "clear-state":
+			f.clearState = on
+		}
+	}
+	return f
+}
+
+// parseStartFlags handles `[--no-state] [--clear-state]` and returns the
+// positional arguments.
+func parseStartFlags(name string, args []string) (startFlags, []string, error) {
+	var f startFlags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&f.noState, "no-state", false, "neither restore nor save the UI state")
+	fs.BoolVar(&f.clearState, "clear-state", false, "delete the saved UI state before starting")
+	if name == "show" {
+		fs.BoolVar(&f.isolated, "isolated", false, "run the story alone, without the book around it")
+	}
+	if err := parseInterspersed(fs, args); err != nil {
+		return f, nil, err
+	}
+	return f, fs.Args(), nil
+}
+
+// runList handles `list`. A story's group and its presets are all that is
+// known about it besides its name.
+func runList(args []string, o options) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STORY\tGROUP\tPRESETS")
+	for _, entry := range components {
+		group := models.StoryGroup(entry.Name)
+		if group == "" {
+			group = "-"
+		}
+		presets := "-"
+		if len(entry.Presets) > 0 {
+			labels := make([]string, len(entry.Presets))
+			for i, preset := range entry.Presets {
+				labels[i] = preset.Label
+			}
+			presets = strings.Join(labels, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entry.Name, group, presets)
+	}
+	return tw.Flush()
+}
+
+// runShow handles `show <story>`.
+func runShow(args []string, o options) error {
+	flags, rest, err := parseStartFlags("show", args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageError{errors.New("show needs the name of one story")}
+	}
+	if _, err := lookup(rest[0]); err != nil {
+		return err
+	}
+	if flags.isolated {
+		return runIsolated(rest[0], o)
+	}
+	return runTUI(flags, o, rest[0])
+}
+
+// runRender handles `render <story> [--size WxH] [--plain]`.
+func runRender(args []string, o options) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	size := fs.String("size", "80x24", "size to render the story at, as WIDTHxHEIGHT")
+	plain := fs.Bool("plain", false, "leave out colours and other styling")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError{errors.New("render needs the name of one story")}
+	}
+
+	width, height, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+	entry, err := lookup(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	view, err := renderEntry(entry, width, height)
+	if err != nil {
+		return err
+	}
+	if *plain {
+		view = ansi.Strip(view)
+	}
+	fmt.Println(view)
+	return nil
+}
+
+// runCheck handles `check [--size WxH] [--strict]`. It fails when any story
+// panics while it is created, initialised, sized or rendered, and with
+// --strict also when a story's view has layout mistakes.
+func runCheck(args []string, o options) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	size := fs.String("size", "80x24", "size to render each story at, as WIDTHxHEIGHT")
+	strict := fs.Bool("strict", false, "fail on layout mistakes too, such as lines wider than the story was given")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	width, height, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+
+	// Lint the views in colour, as the TUI shows them, so styles that are
+	// not reset and styled padding can be told apart
+	defer forceColor()()
+
+	failed, warned := 0, 0
+	for _, entry := range components {
+		warnings, err := checkEntry(entry, width, height)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", entry.Name, err)
+			continue
+		}
+		if len(warnings) == 0 {
+			fmt.Printf("ok    %s\n", entry.Name)
+			continue
+		}
+
+		warned++
+		label := "warn"
+		if *strict {
+			failed++
+			label = "FAIL"
+		}
+		fmt.Printf("%-4s  %s: layout\n", label, entry.Name)
+		for _, w := range warnings {
+			fmt.Printf("      %s\n", w)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d stories failed", failed, len(components))
+	}
+	if warned > 0 {
+		fmt.Printf("All %d stories rendered at %dx%d, %d with layout warnings\n", len(components), width, height, warned)
+		return nil
+	}
+	fmt.Printf("All %d stories rendered at %dx%d\n", len(components), width, height)
+	return nil
+}
+
+// checkEntry creates, initialises and renders a story, returning the layout
+// mistakes in its view and reporting a panic as an error. The story's
+// commands are not run.
+func checkEntry(entry models.ComponentEntry, width, height int) (warnings []lint.Warning, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("component panicked: %v", r)
+		}
+	}()
+
+	model := entry.Factory()
+	model.Init()
+	return headless.Lint(model, width, height)
+}
+
+// runServe handles `serve [--http addr] [--allow-remote] [--token token]
+// [--ssh addr] [--host-key file]`. Without an address, the book is served to
+// browsers on localhost:6006. Neither browsers nor SSH clients are
+// authenticated by default, so both are only served on loopback addresses.
+// --allow-remote lets browsers in from any address that --http listens on,
+// as long as they open the page with a token, which is generated unless
+// given with --token; books that serve SSH clients on other machines call ServeSSH with
+// an authentication option instead.
+func runServe(args []string, o options) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	httpAddr := fs.String("http", "", "address to serve the book to browsers on, loopback unless --allow-remote is given (default "+defaultWebAddr+")")
+	allowRemote := fs.Bool("allow-remote", false, "let browsers in from other machines when they open the page with the token")
+	token := fs.String("token", "", "token browsers open the page with, which implies --allow-remote (generated by default)")
+	sshAddr := fs.String("ssh", "", "loopback address to serve the book to SSH clients on, such as localhost:2222")
+	hostKey := fs.String("host-key", "", "PEM file holding the SSH host key, created when missing")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Errorf("unexpected argument %q", fs.Arg(0))}
+	}
+	if *httpAddr == "" && *sshAddr == "" {
+		*httpAddr = defaultWebAddr
+	}
+	if *hostKey != "" {
+		o.ssh.hostKeyFile = *hostKey
+	}
+	if *token == "" && *allowRemote {
+		generated, err := newWebToken()
+		if err != nil {
+			return err
+		}
+		*token = generated
+	}
+	o.web.token = *token
+
+	// Both servers run until one of them fails
+	errs := make(chan error, 2)
+	if *httpAddr != "" {
+		l, err := listenWeb(*httpAddr, o)
+		if errors.Is(err, errNoWebAuth) {
+			return errors.New("serve does not authenticate browsers without a token, so --http must be a loopback address such as localhost:6006, or --allow-remote must be given")
+		}
+		if err != nil {
+			return err
+		}
+		page := "http://" + l.Addr().String() + "/"
+		if o.web.token != "" {
+			page += "?token=" + url.QueryEscape(o.web.token)
+		}
+		fmt.Printf("Serving %d stories to browsers on %s\n", len(components), page)
+		go func() {
+			errs <- http.Serve(l, newWebHandler(o))
+		}()
+	}
+	if *sshAddr != "" {
+		server, err := newSSHServer(o)
+		if err != nil {
+			return err
+		}
+		l, err := net.Listen("tcp", *sshAddr)
+		if err != nil {
+			return err
+		}
+		if err := server.checkListener(l); err != nil {
+			l.Close()
+			return errors.New("serve does not authenticate SSH clients, so --ssh must be a loopback address such as localhost:2222")
+		}
+		fmt.Printf("Serving %d stories over SSH on %s\n", len(components), l.Addr())
+		go func() {
+			errs <- server.Serve(l)
+		}()
+	}
+	return <-errs
+}
+
+// runContrast handles `contrast [story...] [--size WxH] [--min ratio]
+// [--background color]`. It fails when any text in the stories, all of them
+// by default, has a contrast ratio below the minimum.
+func runContrast(args []string, o options) error {
+	def := contrast.DefaultOptions()
+	fs := flag.NewFlagSet("contrast", flag.ContinueOnError)
+	size := fs.String("size", "80x24", "size to render each story at, as WIDTHxHEIGHT")
+	minRatio := fs.Float64("min", def.MinRatio, "lowest acceptable contrast ratio")
+	background := fs.String("background", "", "background behind stories that set none, as #rrggbb or an ANSI colour number")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	width, height, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+	if *minRatio < 1 || *minRatio > 21 {
+		return usageError{fmt.Errorf("invalid ratio %v, expected a number from 1 to 21", *minRatio)}
+	}
+
+	// Colours given as lipgloss.Color are resolved with the colour profile
+	defer forceColor()()
+	opts := def
+	if o.model.PreviewBackground != nil {
+		opts = contrast.OnBackground(o.model.PreviewBackground)
+	}
+	if *background != "" {
+		bg, err := parseColor(*background)
+		if err != nil {
+			return usageError{err}
+		}
+		opts = contrast.OnBackground(bg)
+	}
+	opts.MinRatio = *minRatio
+
+	entries := components
+	if fs.NArg() > 0 {
+		entries = nil
+		for _, name := range fs.Args() {
+			entry, err := lookup(name)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	failed := 0
+	for _, entry := range entries {
+		regions, err := AuditContrast(entry.Name, width, height, opts)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", entry.Name, err)
+			continue
+		}
+		if len(regions) == 0 {
+			fmt.Printf("ok    %s\n", entry.Name)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL  %s: %d runs of text below %.1f:1\n", entry.Name, len(regions), opts.MinRatio)
+		for _, r := range regions {
+			fmt.Printf("      %d,%d  %4.1f:1  %s on %s  %q\n",
+				r.X, r.Y, r.Ratio, hexColor(r.Foreground), hexColor(r.Background), strings.TrimSpace(r.Text))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d stories have text with too little contrast", failed, len(entries))
+	}
+	fmt.Printf("All %d stories reach a contrast of %.1f:1\n", len(entries), opts.MinRatio)
+	return nil
+}
+
+// runExportHTML handles `export-html [dir] [--size WxH]`.
+func runExportHTML(args []string, o options) error {
+	fs := flag.NewFlagSet("export-html", flag.ContinueOnError)
+	size := fs.String("size", "80x24", "size to render each component at, as WIDTHxHEIGHT")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	width, height, err := parseSize(*size)
+	if err != nil {
+		return err
+	}
+
+	dir := "bubblebook-html"
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	if err := ExportHTML(dir, width, height); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d components to %s\n", len(components), dir)
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, leaving the positional arguments in fs.Args().
+func parseInterspersed(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return usageError{err}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if err := fs.Parse(positional); err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+// parseSize parses a size written as WIDTHxHEIGHT.
+func parseSize(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(strings.ToLower(s), "x")
+	if ok {
+		width, err = strconv.Atoi(w)
+		if err == nil {
+			height, err = strconv.Atoi(h)
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q, expected WIDTHxHEIGHT such as 80x24", s)
+	}
+	return width, height, nil
+}
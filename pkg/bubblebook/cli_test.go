@@ -0,0 +1,40 @@
+package bubblebook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+func TestUnknownCommand(t *testing.T) {
+	withStories(t)
+
+	err := runCLI([]string{"lsit", "--no-state"}, options{model: models.DefaultConfig()})
+	var usage usageError
+	if !errors.As(err, &usage) {
+		t.Fatalf("runCLI() = %v, want a usage error", err)
+	}
+	if want := `unknown command "lsit"`; err.Error() != want {
+		t.Errorf("runCLI() = %q, want %q", err, want)
+	}
+}
+
+func TestScanStartFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want startFlags
+	}{
+		{nil, startFlags{}},
+		{[]string{"--no-state"}, startFlags{noState: true}},
+		{[]string{"-clear-state", "--port", "8080"}, startFlags{clearState: true}},
+		{[]string{"--no-state=false", "--clear-state=true"}, startFlags{clearState: true}},
+		{[]string{"--verbose", "--", "--no-state"}, startFlags{}},
+	}
+
+	for _, tt := range tests {
+		if got := scanStartFlags(tt.args); got != tt.want {
+			t.Errorf("scanStartFlags(%q) = %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package console turns the lines typed into the message console into
+// messages for a component, such as "key ctrl+c", "resize 50x12" or
+// "click 4 2".
+package console
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ParseFunc turns the arguments of a command into a message
+type ParseFunc func(args string) (tea.Msg, error)
+
+// builtin are the commands every book understands, with their usage
+var builtin = map[string]struct {
+	usage string
+	parse func(args string) ([]tea.Msg, error)
+}{
+	"key":    {"key <key>...  such as key ctrl+c or key alt+up", parseKeys},
+	"type":   {"type <text>  one key per character", parseType},
+	"paste":  {"paste <text>", parsePaste},
+	"resize": {"resize <width>x<height>", parseResize},
+	"click":  {"click <x> <y> [left|middle|right]", parseClick},
+	"mouse":  {"mouse <press|release|motion|wheelup|wheeldown> <x> <y>", parseMouse},
+	"focus":  {"focus", noArgs(tea.FocusMsg{})},
+	"blur":   {"blur", noArgs(tea.BlurMsg{})},
+}
+
+var (
+	customMu sync.RWMutex
+	custom   = make(map[string]ParseFunc)
+)
+
+// CheckName reports why a name cannot be given to a custom command: it is
+// empty, contains spaces or is taken by a built-in command.
+func CheckName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid command name %q", name)
+	}
+	if _, ok := builtin[name]; ok {
+		return fmt.Errorf("%q is a built-in command", name)
+	}
+	return nil
+}
+
+// Register adds a command that sends a custom message, built from the rest
+// of the line by parse, whatever story is active. Names of built-in
+// commands cannot be taken.
+func Register(name string, parse ParseFunc) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+
+	customMu.Lock()
+	defer customMu.Unlock()
+	custom[name] = parse
+	return nil
+}
+
+// Commands returns the names of every command, built-in, registered and
+// those of the active story, in alphabetical order
+func Commands(story map[string]ParseFunc) []string {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	names := make([]string, 0, len(builtin)+len(custom)+len(story))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	for name := range custom {
+		names = append(names, name)
+	}
+	for name := range story {
+		if _, ok := custom[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Usage describes a built-in command's arguments, or returns "" for
+// registered ones
+func Usage(name string) string {
+	return builtin[name].usage
+}
+
+// Parse turns a line into the messages it stands for, in the order they
+// are to be sent. story holds the commands of the active story, which take
+// precedence over registered ones of the same name.
+func Parse(line string, story map[string]ParseFunc) ([]tea.Msg, error) {
+	name, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if name == "" {
+		return nil, errors.New("type a command, such as key enter")
+	}
+	// Any number of spaces can separate the command from its arguments
+	args = strings.TrimLeft(args, " ")
+
+	if cmd, ok := builtin[name]; ok {
+		return cmd.parse(args)
+	}
+
+	parse, ok := story[name]
+	if !ok {
+		customMu.RLock()
+		parse, ok = custom[name]
+		customMu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q, expected one of %s", name, strings.Join(Commands(story), ", "))
+	}
+
+	msg, err := parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return []tea.Msg{msg}, nil
+}
+
+// keyTypes maps key names, as tea.KeyMsg.String returns them, to key types
+var keyTypes = func() map[string]tea.KeyType {
+	types := map[string]tea.KeyType{"space": tea.KeySpace}
+	for k := tea.KeyType(-100); k < 128; k++ {
+		if name := k.String(); name != "" && k != tea.KeyRunes {
+			types[name] = k
+		}
+	}
+	return types
+}()
+
+// ParseKey turns a key written as tea.KeyMsg.String writes it, such as "a",
+// "enter", "ctrl+c" or "alt+up", into a key message
+func ParseKey(s string) (tea.KeyMsg, error) {
+	var key tea.Key
+	name := s
+	if rest, ok := strings.CutPrefix(s, "alt+"); ok && rest != "" {
+		key.Alt = true
+		name = rest
+	}
+
+	if t, ok := keyTypes[name]; ok && name != " " {
+		key.Type = t
+		if t == tea.KeySpace {
+			key.Runes = []rune{' '}
+		}
+		return tea.KeyMsg(key), nil
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		key.Type = tea.KeyRunes
+		key.Runes = runes
+		return tea.KeyMsg(key), nil
+	}
+	return tea.KeyMsg{}, fmt.Errorf("unknown key %q", s)
+}
+
+func parseKeys(args string) ([]tea.Msg, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, errors.New("key needs at least one key, such as key enter")
+	}
+
+	msgs := make([]tea.Msg, len(fields))
+	for i, field := range fields {
+		key, err := ParseKey(field)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = key
+	}
+	return msgs, nil
+}
+
+func parseType(args string) ([]tea.Msg, error) {
+	if args == "" {
+		return nil, errors.New("type needs some text")
+	}
+	var msgs []tea.Msg
+	for _, r := range args {
+		if r == ' ' {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+			continue
+		}
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs, nil
+}
+
+func parsePaste(args string) ([]tea.Msg, error) {
+	if args == "" {
+		return nil, errors.New("paste needs some text")
+	}
+	return []tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(args), Paste: true}}, nil
+}
+
+func parseResize(args string) ([]tea.Msg, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(args)), "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid size %q, expected WIDTHxHEIGHT such as 50x12", strings.TrimSpace(args))
+	}
+	return []tea.Msg{tea.WindowSizeMsg{Width: width, Height: height}}, nil
+}
+
+// mouseButtons are the buttons click accepts
+var mouseButtons = map[string]tea.MouseButton{
+	"left":   tea.MouseButtonLeft,
+	"middle": tea.MouseButtonMiddle,
+	"right":  tea.MouseButtonRight,
+}
+
+func parseClick(args string) ([]tea.Msg, error) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, errors.New("click needs a position, such as click 4 2")
+	}
+	x, y, err := parsePosition(fields[0], fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	button := tea.MouseButtonLeft
+	if len(fields) == 3 {
+		var ok bool
+		if button, ok = mouseButtons[fields[2]]; !ok {
+			return nil, fmt.Errorf("unknown button %q, expected left, middle or right", fields[2])
+		}
+	}
+
+	press := tea.MouseEvent{X: x, Y: y, Button: button, Action: tea.MouseActionPress}
+	release := tea.MouseEvent{X: x, Y: y, Button: tea.MouseButtonNone, Action: tea.MouseActionRelease}
+	press.Type = mouseEventType(press)
+	release.Type = tea.MouseRelease
+	return []tea.Msg{tea.MouseMsg(press), tea.MouseMsg(release)}, nil
+}
+
+// mouseActions are the events mouse accepts
+var mouseActions = map[string]tea.MouseEvent{
+	"press":     {Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Type: tea.MouseLeft},
+	"release":   {Button: tea.MouseButtonNone, Action: tea.MouseActionRelease, Type: tea.MouseRelease},
+	"motion":    {Button: tea.MouseButtonNone, Action: tea.MouseActionMotion, Type: tea.MouseMotion},
+	"wheelup":   {Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress, Type: tea.MouseWheelUp},
+	"wheeldown": {Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress, Type: tea.MouseWheelDown},
+}
+
+func parseMouse(args string) ([]tea.Msg, error) {
+	fields := strings.Fields(args)
+	if len(fields) != 3 {
+		return nil, errors.New("mouse needs an event and a position, such as mouse wheeldown 4 2")
+	}
+	event, ok := mouseActions[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown mouse event %q, expected press, release, motion, wheelup or wheeldown", fields[0])
+	}
+	x, y, err := parsePosition(fields[1], fields[2])
+	if err != nil {
+		return nil, err
+	}
+	event.X, event.Y = x, y
+	return []tea.Msg{tea.MouseMsg(event)}, nil
+}
+
+// parsePosition parses the column and row of a cell, counted from the top
+// left corner of the component
+func parsePosition(col, row string) (x, y int, err error) {
+	x, errX := strconv.Atoi(col)
+	y, errY := strconv.Atoi(row)
+	if errX != nil || errY != nil || x < 0 || y < 0 {
+		return 0, 0, fmt.Errorf("invalid position %s %s, expected a column and a row from 0", col, row)
+	}
+	return x, y, nil
+}
+
+// mouseEventType fills in the deprecated Type field of a button press,
+// which older components still switch on
+func mouseEventType(e tea.MouseEvent) tea.MouseEventType {
+	switch e.Button {
+	case tea.MouseButtonMiddle:
+		return tea.MouseMiddle
+	case tea.MouseButtonRight:
+		return tea.MouseRight
+	}
+	return tea.MouseLeft
+}
+
+// noArgs returns a parser for a command that takes no arguments
+func noArgs(msg tea.Msg) func(args string) ([]tea.Msg, error) {
+	return func(args string) ([]tea.Msg, error) {
+		if strings.TrimSpace(args) != "" {
+			return nil, fmt.Errorf("unexpected arguments %q", strings.TrimSpace(args))
+		}
+		return []tea.Msg{msg}, nil
+	}
+}
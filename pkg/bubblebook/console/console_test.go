@@ -0,0 +1,199 @@
+package console
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// key builds a key message of a type
+func key(t tea.KeyType) tea.KeyMsg {
+	return tea.KeyMsg{Type: t}
+}
+
+// runes builds a key message typing text
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+// space is the message the space bar sends
+var space = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want tea.KeyMsg
+		err  bool
+	}{
+		{key: "a", want: runes("a")},
+		{key: "A", want: runes("A")},
+		{key: "enter", want: key(tea.KeyEnter)},
+		{key: "ctrl+c", want: key(tea.KeyCtrlC)},
+		{key: "space", want: space},
+		{key: "alt+up", want: tea.KeyMsg{Type: tea.KeyUp, Alt: true}},
+		{key: "alt+x", want: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true}},
+		{key: "shift+tab", want: key(tea.KeyShiftTab)},
+		{key: "alt+", err: true},
+		{key: "hyper+q", err: true},
+		{key: "", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseKey(tt.key)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseKey(%q) = %v, want an error", tt.key, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseKey(%q) failed: %v", tt.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKey(%q) = %#v, want %#v", tt.key, got, tt.want)
+		}
+		// Keys are written the way Bubble Tea names them, but for space,
+		// which it names " "
+		if got.String() != tt.key && tt.key != "space" {
+			t.Errorf("ParseKey(%q) names the key %q", tt.key, got.String())
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want []tea.Msg
+		err  bool
+	}{
+		{line: "key enter", want: []tea.Msg{key(tea.KeyEnter)}},
+		{line: "  key   up down  ", want: []tea.Msg{key(tea.KeyUp), key(tea.KeyDown)}},
+		{line: "key", err: true},
+		{line: "type hi", want: []tea.Msg{runes("h"), runes("i")}},
+		{line: "type  hi", want: []tea.Msg{runes("h"), runes("i")}},
+		{line: "type a b", want: []tea.Msg{runes("a"), space, runes("b")}},
+		{line: "type", err: true},
+		{line: "paste hello world", want: []tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hello world"), Paste: true}}},
+		{line: "paste   x", want: []tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Paste: true}}},
+		{line: "paste", err: true},
+		{line: "resize 50x12", want: []tea.Msg{tea.WindowSizeMsg{Width: 50, Height: 12}}},
+		{line: "resize 50X12", want: []tea.Msg{tea.WindowSizeMsg{Width: 50, Height: 12}}},
+		{line: "resize 50", err: true},
+		{line: "resize 0x12", err: true},
+		{line: "resize axb", err: true},
+		{line: "focus", want: []tea.Msg{tea.FocusMsg{}}},
+		{line: "blur now", err: true},
+		{line: "", err: true},
+		{line: "jump 3", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.line, nil)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", tt.line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseMouse(t *testing.T) {
+	press := func(x, y int, b tea.MouseButton, typ tea.MouseEventType) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Button: b, Action: tea.MouseActionPress, Type: typ}
+	}
+	release := func(x, y int) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonNone, Action: tea.MouseActionRelease, Type: tea.MouseRelease}
+	}
+
+	tests := []struct {
+		line string
+		want []tea.Msg
+		err  bool
+	}{
+		{line: "click 4 2", want: []tea.Msg{press(4, 2, tea.MouseButtonLeft, tea.MouseLeft), release(4, 2)}},
+		{line: "click 0 0 right", want: []tea.Msg{press(0, 0, tea.MouseButtonRight, tea.MouseRight), release(0, 0)}},
+		{line: "click 1 1 middle", want: []tea.Msg{press(1, 1, tea.MouseButtonMiddle, tea.MouseMiddle), release(1, 1)}},
+		{line: "click 4", err: true},
+		{line: "click 4 2 thumb", err: true},
+		{line: "click -1 2", err: true},
+		{line: "mouse wheeldown 4 2", want: []tea.Msg{press(4, 2, tea.MouseButtonWheelDown, tea.MouseWheelDown)}},
+		{line: "mouse release 3 5", want: []tea.Msg{release(3, 5)}},
+		{line: "mouse motion 7 1", want: []tea.Msg{tea.MouseMsg{X: 7, Y: 1, Button: tea.MouseButtonNone, Action: tea.MouseActionMotion, Type: tea.MouseMotion}}},
+		{line: "mouse drag 4 2", err: true},
+		{line: "mouse press 4", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.line, nil)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", tt.line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// errMsg is a custom message sent by the commands of the tests
+type errMsg struct {
+	reason string
+}
+
+func TestStoryCommands(t *testing.T) {
+	story := map[string]ParseFunc{
+		"fail": func(args string) (tea.Msg, error) {
+			if args == "" {
+				return nil, errors.New("needs a reason")
+			}
+			return errMsg{args}, nil
+		},
+	}
+
+	got, err := Parse("fail  timeout", story)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if want := []tea.Msg{errMsg{"timeout"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %#v, want %#v", got, want)
+	}
+
+	if _, err := Parse("fail", story); err == nil || err.Error() != "fail: needs a reason" {
+		t.Errorf("Parse() = %v, want the command's error", err)
+	}
+	if _, err := Parse("fail timeout", nil); err == nil {
+		t.Error("a story's command was understood while another story is active")
+	}
+	if names := Commands(story); !slices.Contains(names, "fail") || !slices.IsSorted(names) {
+		t.Errorf("Commands() = %v, want the story's command among them, sorted", names)
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"", "two words", "tab\tbed", "key", "resize"} {
+		if CheckName(name) == nil {
+			t.Errorf("CheckName(%q) let the name be taken", name)
+		}
+	}
+	if err := CheckName("fail"); err != nil {
+		t.Errorf("CheckName(%q) = %v", "fail", err)
+	}
+}
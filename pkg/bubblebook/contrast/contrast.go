@@ -0,0 +1,200 @@
+// Package contrast finds text in rendered views whose colours are too close
+// to read, using the WCAG contrast ratio.
+package contrast
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+)
+
+// Options configures an audit
+type Options struct {
+	// Foreground and Background are the terminal's default colours, used
+	// for cells without explicit colours
+	Foreground color.Color
+	Background color.Color
+	// MinRatio is the lowest acceptable contrast ratio
+	MinRatio float64
+}
+
+// DefaultOptions returns the options used when none are given: the default
+// colours of the exports and the WCAG AA ratio for normal text
+func DefaultOptions() Options {
+	return Options{
+		Foreground: screen.DefaultForeground,
+		Background: screen.DefaultBackground,
+		MinRatio:   4.5,
+	}
+}
+
+// OnBackground returns DefaultOptions for a terminal with the given
+// background. Light backgrounds get dark default text.
+func OnBackground(background color.Color) Options {
+	opts := DefaultOptions()
+	if Ratio(opts.Background, background) > Ratio(opts.Foreground, background) {
+		opts.Foreground = opts.Background
+	}
+	opts.Background = background
+	return opts
+}
+
+// Region is a run of text on one row drawn in the same colours
+type Region struct {
+	// X and Y are the cell the run starts at, and Width is the number of
+	// cells it covers
+	X, Y, Width int
+	Text        string
+	// Foreground and Background are the effective colours of the text
+	Foreground color.Color
+	Background color.Color
+	// Ratio is the contrast ratio between them, from 1 to 21
+	Ratio float64
+}
+
+// Audit lays out a view of the given size and returns the runs of text
+// whose contrast is below opts.MinRatio, row by row. Runs of blank cells
+// are ignored. Zero options fall back to DefaultOptions. A width or height
+// of zero or less is measured from the view.
+func Audit(view string, width, height int, opts Options) []Region {
+	opts = withDefaults(opts)
+	buf := screen.Parse(view, width, height)
+
+	var failing []Region
+	for y := 0; y < buf.Height(); y++ {
+		for _, r := range runs(buf, y, opts) {
+			if r.Ratio < opts.MinRatio {
+				failing = append(failing, r)
+			}
+		}
+	}
+	return failing
+}
+
+// withDefaults fills in zero options
+func withDefaults(opts Options) Options {
+	def := DefaultOptions()
+	if opts.Foreground == nil {
+		opts.Foreground = def.Foreground
+	}
+	if opts.Background == nil {
+		opts.Background = def.Background
+	}
+	if opts.MinRatio <= 0 {
+		opts.MinRatio = def.MinRatio
+	}
+	return opts
+}
+
+// runs splits a row into runs of cells with the same effective colours and
+// returns those that contain text
+func runs(buf *cellbuf.Buffer, y int, opts Options) []Region {
+	var (
+		found []Region
+		run   Region
+		text  strings.Builder
+	)
+	flush := func() {
+		if run.Width > 0 && strings.TrimSpace(text.String()) != "" {
+			run.Text = text.String()
+			run.Ratio = Ratio(run.Foreground, run.Background)
+			found = append(found, run)
+		}
+		run = Region{}
+		text.Reset()
+	}
+
+	for x := 0; x < buf.Width(); x++ {
+		c := buf.Cell(x, y)
+		if c == nil || c.Width == 0 {
+			// Placeholders of wide characters belong to the cell before
+			continue
+		}
+
+		fg, bg := cellColors(c, opts)
+		if run.Width > 0 && (!sameColor(fg, run.Foreground) || !sameColor(bg, run.Background)) {
+			flush()
+		}
+		if run.Width == 0 {
+			run = Region{X: x, Y: y, Foreground: fg, Background: bg}
+		}
+
+		run.Width += c.Width
+		if c.Rune == 0 {
+			text.WriteByte(' ')
+		} else {
+			text.WriteRune(c.Rune)
+			for _, r := range c.Comb {
+				text.WriteRune(r)
+			}
+		}
+	}
+	flush()
+	return found
+}
+
+// cellColors resolves the colours a cell is drawn in, applying reverse
+// video and faint text, which terminals draw halfway to the background
+func cellColors(c *cellbuf.Cell, opts Options) (fg, bg color.Color) {
+	fg, bg = c.Style.Fg, c.Style.Bg
+	if fg == nil {
+		fg = opts.Foreground
+	}
+	if bg == nil {
+		bg = opts.Background
+	}
+	if c.Style.Attrs&cellbuf.ReverseAttr != 0 {
+		fg, bg = bg, fg
+	}
+	if c.Style.Attrs&cellbuf.FaintAttr != 0 {
+		fg = blend(fg, bg)
+	}
+	return fg, bg
+}
+
+// blend mixes two colours equally
+func blend(a, b color.Color) color.Color {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return color.RGBA64{
+		R: uint16((ar + br) / 2),
+		G: uint16((ag + bg) / 2),
+		B: uint16((ab + bb) / 2),
+		A: 0xffff,
+	}
+}
+
+// sameColor compares two colours by their RGB values
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return ar == br && ag == bg && ab == bb
+}
+
+// Ratio returns the WCAG contrast ratio of two colours, from 1 for equal
+// colours to 21 for black on white
+func Ratio(a, b color.Color) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance returns the relative luminance of a colour as defined by WCAG
+func luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// linear converts a 16-bit sRGB channel to linear light
+func linear(v uint32) float64 {
+	s := float64(v) / 0xffff
+	if s <= 0.04045 {
+		return s / 12.92
+	}
+	return math.Pow((s+0.055)/1.055, 2.4)
+}
@@ -0,0 +1,82 @@
+package contrast
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b color.Color
+		want float64
+	}{
+		{"black on white", color.Black, color.White, 21},
+		{"equal colours", color.RGBA{0x77, 0x77, 0x77, 0xff}, color.RGBA{0x77, 0x77, 0x77, 0xff}, 1},
+		{"grey on white", color.RGBA{0x77, 0x77, 0x77, 0xff}, color.White, 4.48},
+		{"white on grey", color.White, color.RGBA{0x77, 0x77, 0x77, 0xff}, 4.48},
+		{"blue on white", color.RGBA{0x00, 0x00, 0xff, 0xff}, color.White, 8.59},
+		{"defaults", DefaultOptions().Foreground, DefaultOptions().Background, 11.05},
+	}
+
+	for _, tt := range tests {
+		if got := Ratio(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: Ratio() = %.3f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name string
+		view string
+		want []string
+	}{
+		{
+			name: "default colours",
+			view: "plain text",
+		},
+		{
+			name: "dark text",
+			view: "ok \x1b[38;2;40;40;40mhidden\x1b[0m ok",
+			want: []string{"hidden"},
+		},
+		{
+			name: "faint text",
+			view: "\x1b[2;38;2;119;119;119mfaint\x1b[0m",
+			want: []string{"faint"},
+		},
+		{
+			name: "reverse video",
+			view: "\x1b[7;38;2;40;40;40mbadge\x1b[0m",
+			want: []string{"badge"},
+		},
+		{
+			name: "blank cells",
+			view: "\x1b[38;2;28;28;28m     \x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, r := range Audit(tt.view, 0, 0, DefaultOptions()) {
+			got = append(got, r.Text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Audit() flagged %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOnBackground(t *testing.T) {
+	light := OnBackground(color.White)
+	if Ratio(light.Foreground, color.White) < 4.5 {
+		t.Errorf("a light background gets default text with a ratio of %.1f", Ratio(light.Foreground, color.White))
+	}
+	dark := OnBackground(color.Black)
+	if dark.Foreground != DefaultOptions().Foreground {
+		t.Errorf("a dark background gets default text %v, want %v", dark.Foreground, DefaultOptions().Foreground)
+	}
+}
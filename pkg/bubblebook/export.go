@@ -0,0 +1,132 @@
+package bubblebook
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/export"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/headless"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// ExportSVG renders a registered component at the given size and writes it
+// to w as an SVG image.
+func ExportSVG(w io.Writer, name string, width, height int, opts export.SVGOptions) error {
+	entry, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	view, err := renderEntry(entry, width, height)
+	if err != nil {
+		return err
+	}
+
+	if opts.Title == "" {
+		opts.Title = entry.Name
+	}
+	return export.SVG(w, view, width, height, opts)
+}
+
+// ExportHTML renders every registered component at the given size and
+// writes a static HTML catalogue of them to dir. Each preset of a story is
+// shown as a further state, reached by sending its messages to a fresh
+// instance.
+func ExportHTML(dir string, width, height int) error {
+	stories := make([]export.CatalogueStory, len(components))
+	for i, entry := range components {
+		view, err := renderEntry(entry, width, height)
+		stories[i] = export.CatalogueStory{
+			ID:     entry.Name,
+			Width:  width,
+			Height: height,
+			View:   view,
+			Err:    err,
+		}
+		if group := models.StoryGroup(entry.Name); group != "" {
+			stories[i].Metadata = map[string]string{"Group": group}
+		}
+		for _, preset := range entry.Presets {
+			view, err := playEntry(entry, width, height, preset.Msgs)
+			stories[i].Steps = append(stories[i].Steps, export.CatalogueStep{
+				Label: preset.Label,
+				View:  view,
+				Err:   err,
+			})
+		}
+	}
+
+	return export.WriteCatalogue(dir, "Bubblebook", stories)
+}
+
+// playEntry creates a fresh instance of a component, sends it some
+// messages and renders the state it ends up in.
+func playEntry(entry models.ComponentEntry, width, height int, msgs []tea.Msg) (view string, err error) {
+	defer forceColor()()
+	err = headless.Play(entry.Factory(), width, height, msgs, func(step int, v string) {
+		view = v
+	})
+	return view, err
+}
+
+// castStepInterval is the time between scripted steps in a recording.
+const castStepInterval = 500 * time.Millisecond
+
+// RecordCast plays a script of messages against a fresh instance of a
+// registered component and writes the frames to w as an asciicast v2
+// recording, one step every half second.
+func RecordCast(w io.Writer, name string, width, height int, script []tea.Msg) error {
+	entry, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	defer forceColor()()
+
+	start := time.Now()
+	recorder := export.NewCastRecorder(width, height, entry.Name, start)
+	err = headless.Play(entry.Factory(), width, height, script, func(step int, view string) {
+		recorder.Frame(start.Add(time.Duration(step)*castStepInterval), view)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = recorder.WriteTo(w)
+	return err
+}
+
+// lookup finds a registered component by name.
+func lookup(name string) (models.ComponentEntry, error) {
+	for _, entry := range components {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return models.ComponentEntry{}, fmt.Errorf("no component registered as %q", name)
+}
+
+// renderEntry creates a fresh instance of a component and renders it in
+// colour, even when stdout is not a terminal.
+func renderEntry(entry models.ComponentEntry, width, height int) (string, error) {
+	defer forceColor()()
+	return headless.Render(entry.Factory(), width, height)
+}
+
+// forceColor makes lipgloss emit colours when stdout is not a terminal,
+// until the returned function puts the previous colour profile back.
+// Stories style their views with the default renderer, so it is the one
+// that has to change while they are rendered.
+func forceColor() (restore func()) {
+	previous := lipgloss.ColorProfile()
+	if previous != termenv.Ascii {
+		return func() {}
+	}
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	return func() {
+		lipgloss.SetColorProfile(previous)
+	}
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// CastRecorder collects rendered frames and writes them as an asciicast v2
+// recording. Consecutive identical frames are only stored once.
+type CastRecorder struct {
+	width  int
+	height int
+	title  string
+	start  time.Time
+	last   string
+	events [][3]any
+}
+
+// NewCastRecorder starts a recording of a component of the given size
+func NewCastRecorder(width, height int, title string, start time.Time) *CastRecorder {
+	return &CastRecorder{
+		width:  width,
+		height: height,
+		title:  title,
+		start:  start,
+	}
+}
+
+// Frame records the view as it looked at time t
+func (r *CastRecorder) Frame(t time.Time, view string) {
+	if view == r.last && len(r.events) > 0 {
+		return
+	}
+	r.last = view
+
+	elapsed := math.Round(t.Sub(r.start).Seconds()*1e6) / 1e6
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	r.events = append(r.events, [3]any{elapsed, "o", castFrame(view)})
+}
+
+// Len returns the number of frames recorded so far
+func (r *CastRecorder) Len() int {
+	return len(r.events)
+}
+
+// WriteTo writes the recording in asciicast v2 format
+func (r *CastRecorder) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	enc := json.NewEncoder(cw)
+
+	header := map[string]any{
+		"version":   2,
+		"width":     r.width,
+		"height":    r.height,
+		"timestamp": r.start.Unix(),
+		"env":       map[string]string{"TERM": "xterm-256color"},
+	}
+	if r.title != "" {
+		header["title"] = r.title
+	}
+	if err := enc.Encode(header); err != nil {
+		return cw.n, err
+	}
+
+	for _, event := range r.events {
+		if err := enc.Encode(event); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// castFrame turns a view into terminal output that redraws the whole screen
+func castFrame(view string) string {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range strings.Split(view, "\n") {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[0m\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	return b.String()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCastRecorder(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	r := NewCastRecorder(20, 2, "Counter", start)
+	r.Frame(start, "Count: 0")
+	r.Frame(start.Add(100*time.Millisecond), "Count: 0")
+	r.Frame(start.Add(1500*time.Microsecond+time.Second), "\x1b[1mCount: 1\x1b[0m\nnext")
+	if r.Len() != 2 {
+		t.Errorf("Len() = %d, want repeated frames stored once", r.Len())
+	}
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var lines [][]byte
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and two events", len(lines))
+	}
+
+	var header struct {
+		Version   int               `json:"version"`
+		Width     int               `json:"width"`
+		Height    int               `json:"height"`
+		Timestamp int64             `json:"timestamp"`
+		Title     string            `json:"title"`
+		Env       map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(lines[0], &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 20 || header.Height != 2 ||
+		header.Timestamp != 1700000000 || header.Title != "Counter" || header.Env["TERM"] == "" {
+		t.Errorf("header = %+v", header)
+	}
+
+	events := []struct {
+		time float64
+		data string
+	}{
+		{0, "\x1b[HCount: 0\x1b[0m\x1b[K\x1b[J"},
+		{1.0015, "\x1b[H\x1b[1mCount: 1\x1b[0m\x1b[0m\x1b[K\r\nnext\x1b[0m\x1b[K\x1b[J"},
+	}
+	for i, want := range events {
+		var event []any
+		if err := json.Unmarshal(lines[i+1], &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 || event[0] != want.time || event[1] != "o" || event[2] != want.data {
+			t.Errorf("event %d = %q, want [%v \"o\" %q]", i, event, want.time, want.data)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// CatalogueStory is a single component entry in an HTML catalogue
+type CatalogueStory struct {
+	// ID is the name the component was registered with
+	ID string
+	// Width and Height are the size the snapshot was rendered at
+	Width  int
+	Height int
+	// View is the rendered ANSI output of the component
+	View string
+	// Err is set when the component could not be rendered
+	Err error
+	// Metadata is shown as a table alongside the snapshot
+	Metadata map[string]string
+	// Steps are further states of the story, shown below the snapshot
+	Steps []CatalogueStep
+}
+
+// CatalogueStep is a state a story reaches after being sent some messages,
+// such as one of its presets
+type CatalogueStep struct {
+	Label string
+	// View is the rendered ANSI output of the component in this state
+	View string
+	// Err is set when the state could not be rendered
+	Err error
+}
+
+// catalogueEntry is a story prepared for the templates
+type catalogueEntry struct {
+	CatalogueStory
+	File     string
+	Snapshot template.HTML
+	States   []catalogueState
+}
+
+// catalogueState is a step prepared for the templates
+type catalogueState struct {
+	CatalogueStep
+	Snapshot template.HTML
+}
+
+// cataloguePage is the data passed to the page template
+type cataloguePage struct {
+	Title   string
+	Entries []catalogueEntry
+	Current *catalogueEntry
+}
+
+// WriteCatalogue writes a static HTML catalogue of the given stories to dir:
+// an index with every snapshot and one page per story, all sharing a
+// navigation sidebar with a search box.
+func WriteCatalogue(dir, title string, stories []CatalogueStory) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	entries := make([]catalogueEntry, len(stories))
+	used := make(map[string]bool)
+	for i, story := range stories {
+		entries[i] = catalogueEntry{
+			CatalogueStory: story,
+			File:           uniqueSlug(story.ID, used) + ".html",
+		}
+		if story.Err == nil {
+			entries[i].Snapshot = template.HTML(HTML(story.View, story.Width, story.Height))
+		}
+		for _, step := range story.Steps {
+			state := catalogueState{CatalogueStep: step}
+			if step.Err == nil {
+				state.Snapshot = template.HTML(HTML(step.View, story.Width, story.Height))
+			}
+			entries[i].States = append(entries[i].States, state)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(catalogueCSS), 0o644); err != nil {
+		return err
+	}
+
+	if err := writeCataloguePage(filepath.Join(dir, "index.html"), cataloguePage{
+		Title:   title,
+		Entries: entries,
+	}); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if err := writeCataloguePage(filepath.Join(dir, entries[i].File), cataloguePage{
+			Title:   title,
+			Entries: entries,
+			Current: &entries[i],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCataloguePage renders one page of the catalogue to path
+func writeCataloguePage(path string, page cataloguePage) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := catalogueTemplate.Execute(f, page); err != nil {
+		f.Close()
+		return fmt.Errorf("rendering %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
+
+// uniqueSlug returns the slug of a story ID that is not yet taken
+func uniqueSlug(id string, used map[string]bool) string {
+	slug := Slug(id)
+	candidate := slug
+	for n := 2; used[candidate] || candidate == "index"; n++ {
+		candidate = fmt.Sprintf("%s-%d", slug, n)
+	}
+	used[candidate] = true
+
+	return candidate
+}
+
+// Slug turns a story ID into a file name that is safe on every platform
+func Slug(id string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(id) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "story"
+	}
+	return slug
+}
+
+var catalogueTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Current}}{{.Current.ID}} · {{end}}{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<nav class="bb-sidebar">
+  <a class="bb-brand" href="index.html">{{.Title}}</a>
+  <input class="bb-search" type="search" placeholder="Search components…" aria-label="Search components">
+  <ul class="bb-nav">
+  {{- range .Entries}}
+    <li data-name="{{.ID}}"><a href="{{.File}}"{{if and $.Current (eq .File $.Current.File)}} class="active"{{end}}>{{.ID}}</a></li>
+  {{- end}}
+  </ul>
+</nav>
+<main class="bb-main">
+{{- if .Current}}
+  {{template "story" .Current}}
+{{- else}}
+  <h1>{{.Title}}</h1>
+  <p class="bb-summary">{{len .Entries}} components</p>
+  {{- range .Entries}}
+  <section class="bb-card" data-name="{{.ID}}">
+    <h2><a href="{{.File}}">{{.ID}}</a></h2>
+    {{template "snapshot" .}}
+  </section>
+  {{- end}}
+{{- end}}
+</main>
+<script>
+const search = document.querySelector(".bb-search");
+search.addEventListener("input", () => {
+  const q = search.value.trim().toLowerCase();
+  document.querySelectorAll("[data-name]").forEach(el => {
+    el.hidden = q !== "" && !el.dataset.name.toLowerCase().includes(q);
+  });
+});
+</script>
+</body>
+</html>
+{{define "story"}}
+  <h1>{{.ID}}</h1>
+  {{template "snapshot" .}}
+  <table class="bb-meta">
+    <tr><th>Story</th><td>{{.ID}}</td></tr>
+    <tr><th>Size</th><td>{{.Width}}×{{.Height}}</td></tr>
+    {{- range $key, $value := .Metadata}}
+    <tr><th>{{$key}}</th><td>{{$value}}</td></tr>
+    {{- end}}
+  </table>
+  {{- range .States}}
+  <section class="bb-step">
+    <h2>{{.Label}}</h2>
+    {{template "snapshot" .}}
+  </section>
+  {{- end}}
+{{end}}
+{{define "snapshot"}}
+  {{- if .Err}}
+  <pre class="bb-error">{{.Err}}</pre>
+  {{- else}}
+  <div class="bb-terminal">{{.Snapshot}}</div>
+  {{- end}}
+{{end}}
+`))
+
+const catalogueCSS = `* { box-sizing: border-box; }
+body { margin: 0; display: flex; min-height: 100vh; font-family: system-ui, sans-serif; background: #f5f5f7; color: #222; }
+a { color: inherit; }
+.bb-sidebar { width: 260px; flex-shrink: 0; padding: 16px; background: #1c1c1c; color: #d0d0d0; position: sticky; top: 0; height: 100vh; overflow-y: auto; }
+.bb-brand { display: block; font-weight: bold; color: #ff5faf; text-decoration: none; margin-bottom: 12px; }
+.bb-search { width: 100%; padding: 6px 8px; border: 1px solid #444; border-radius: 4px; background: #2a2a2a; color: inherit; }
+.bb-nav { list-style: none; padding: 0; margin: 12px 0 0; }
+.bb-nav a { display: block; padding: 4px 8px; border-radius: 4px; text-decoration: none; color: #9e9e9e; }
+.bb-nav a:hover, .bb-nav a.active { background: #303030; color: #ff5faf; }
+.bb-main { flex: 1; padding: 24px 32px; min-width: 0; }
+.bb-summary { color: #666; }
+.bb-card { margin-bottom: 32px; }
+.bb-terminal { display: inline-block; max-width: 100%; overflow-x: auto; padding: 16px; border-radius: 8px; background: #1c1c1c; color: #d0d0d0; }
+.bb-screen { margin: 0; font: 14px/1.2 "JetBrains Mono", "Fira Code", Menlo, Consolas, monospace; }
+.bb-error { padding: 16px; border-radius: 8px; background: #fde8e8; color: #a00; white-space: pre-wrap; }
+.bb-meta { margin-top: 16px; border-collapse: collapse; }
+.bb-meta th, .bb-meta td { padding: 4px 12px 4px 0; text-align: left; vertical-align: top; }
+.bb-meta th { color: #666; font-weight: normal; }
+.bb-step { margin-top: 32px; }
+`
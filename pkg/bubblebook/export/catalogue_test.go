@@ -0,0 +1,67 @@
+package export
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	got := HTML("\x1b[31m<b>\x1b[0m & \"x\"", 12, 1)
+	want := `<pre class="bb-screen"><span style="color:#800000">&lt;b&gt;</span> &amp; &#34;x&#34;   </pre>`
+	if got != want {
+		t.Errorf("HTML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCatalogue(t *testing.T) {
+	dir := t.TempDir()
+	stories := []CatalogueStory{
+		{
+			ID:       "Forms/<Login>",
+			Width:    10,
+			Height:   1,
+			View:     "<script>",
+			Metadata: map[string]string{"Group": "Forms & Co"},
+			Steps: []CatalogueStep{
+				{Label: "Filled <in>", View: "a&b"},
+				{Label: "Broken", Err: errors.New("panic <here>")},
+			},
+		},
+		{ID: "Forms/Login", Width: 10, Height: 1, View: "second"},
+		{ID: "Index", Width: 10, Height: 1, Err: errors.New("no view")},
+	}
+	if err := WriteCatalogue(dir, "Book", stories); err != nil {
+		t.Fatal(err)
+	}
+
+	// Story names that share a slug, or take the index's, get their own file
+	for _, name := range []string{"index.html", "style.css", "forms-login.html", "forms-login-2.html", "index-2.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "forms-login.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(page)
+	for _, want := range []string{
+		"<title>Forms/&lt;Login&gt; · Book</title>",
+		"&lt;script&gt;",
+		"Forms &amp; Co",
+		"Filled &lt;in&gt;",
+		"a&amp;b",
+		"panic &lt;here&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("the story's page lacks %q", want)
+		}
+	}
+	if strings.Contains(html, "<Login>") || strings.Contains(html, "<in>") {
+		t.Error("the story's page holds unescaped text")
+	}
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+)
+
+// HTML converts a rendered view of the given size to a <pre> block, with the
+// ANSI styles turned into inline CSS.
+func HTML(view string, width, height int) string {
+	buf := screen.Parse(view, width, height)
+
+	var b strings.Builder
+	b.WriteString(`<pre class="bb-screen">`)
+	for y := 0; y < buf.Height(); y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		writeHTMLLine(&b, buf, y)
+	}
+	b.WriteString("</pre>")
+
+	return b.String()
+}
+
+// writeHTMLLine writes one row, wrapping each run of equally styled cells in
+// a single span
+func writeHTMLLine(b *strings.Builder, buf *cellbuf.Buffer, y int) {
+	var runStyle string
+	var run strings.Builder
+
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runStyle == "" {
+			b.WriteString(html.EscapeString(run.String()))
+		} else {
+			fmt.Fprintf(b, `<span style="%s">%s</span>`, runStyle, html.EscapeString(run.String()))
+		}
+		run.Reset()
+	}
+
+	for x := 0; x < buf.Width(); x++ {
+		c := buf.Cell(x, y)
+		if c == nil || c.Width == 0 {
+			continue
+		}
+
+		style := cssStyle(c)
+		if style != runStyle {
+			flush()
+			runStyle = style
+		}
+		if c.Rune == 0 {
+			run.WriteByte(' ')
+		} else {
+			run.WriteString(c.String())
+		}
+	}
+	flush()
+}
+
+// cssStyle returns the inline CSS for a cell, or an empty string when the
+// cell is unstyled
+func cssStyle(c *cellbuf.Cell) string {
+	var props []string
+
+	fg, bg := c.Style.Fg, c.Style.Bg
+	if c.Style.Attrs&cellbuf.ReverseAttr != 0 {
+		fg, bg = cellColors(c, screen.DefaultForeground, screen.DefaultBackground)
+	}
+	if fg != nil {
+		props = append(props, "color:"+hexColor(fg))
+	}
+	if bg != nil {
+		props = append(props, "background:"+hexColor(bg))
+	}
+
+	attrs := c.Style.Attrs
+	if attrs&cellbuf.BoldAttr != 0 {
+		props = append(props, "font-weight:bold")
+	}
+	if attrs&cellbuf.ItalicAttr != 0 {
+		props = append(props, "font-style:italic")
+	}
+	if attrs&cellbuf.FaintAttr != 0 {
+		props = append(props, "opacity:0.5")
+	}
+	if attrs&cellbuf.ConcealAttr != 0 {
+		props = append(props, "visibility:hidden")
+	}
+
+	var decorations []string
+	if c.Style.UlStyle != cellbuf.NoUnderline {
+		decorations = append(decorations, "underline")
+	}
+	if attrs&cellbuf.StrikethroughAttr != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		props = append(props, "text-decoration:"+strings.Join(decorations, " "))
+	}
+
+	return strings.Join(props, ";")
+}
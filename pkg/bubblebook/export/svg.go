@@ -0,0 +1,295 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+)
+
+// SVGOptions configures how a rendered view is drawn as an SVG image
+type SVGOptions struct {
+	// FontFamily is the CSS font-family used for the terminal text
+	FontFamily string
+	// FontSize is the font size in pixels
+	FontSize float64
+	// LineHeight is the height of a row relative to the font size
+	LineHeight float64
+	// Foreground and Background are used for cells without explicit colours
+	Foreground color.Color
+	Background color.Color
+	// Frame draws a terminal window around the output
+	Frame bool
+	// Title is shown in the window frame's title bar
+	Title string
+	// Padding is the space in pixels between the frame and the text
+	Padding float64
+}
+
+// DefaultSVGOptions returns the options used when none are given
+func DefaultSVGOptions() SVGOptions {
+	return SVGOptions{
+		FontFamily: "'JetBrains Mono', 'Fira Code', Menlo, Consolas, monospace",
+		FontSize:   14,
+		LineHeight: 1.2,
+		Foreground: screen.DefaultForeground,
+		Background: screen.DefaultBackground,
+		Frame:      true,
+		Padding:    16,
+	}
+}
+
+const (
+	// cellAspect is the width of a monospace cell relative to the font size
+	cellAspect = 0.6
+
+	frameBarHeight = 28
+	frameRadius    = 8
+)
+
+// SVG draws a view of the given size as a standalone SVG image. Zero font,
+// size and colour options fall back to DefaultSVGOptions; start from that
+// function to get the window frame and padding as well.
+func SVG(w io.Writer, view string, width, height int, opts SVGOptions) error {
+	opts = withSVGDefaults(opts)
+	buf := screen.Parse(view, width, height)
+
+	cellW := opts.FontSize * cellAspect
+	cellH := opts.FontSize * opts.LineHeight
+	textW := float64(buf.Width()) * cellW
+	textH := float64(buf.Height()) * cellH
+
+	offsetX, offsetY := opts.Padding, opts.Padding
+	if opts.Frame {
+		offsetY += frameBarHeight
+	}
+	totalW := textW + 2*opts.Padding
+	totalH := textH + offsetY + opts.Padding
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(totalW), num(totalH), num(totalW), num(totalH))
+	fmt.Fprintf(bw, `<style>text{font-family:%s;font-size:%spx;white-space:pre;dominant-baseline:text-before-edge}</style>`+"\n",
+		escapeXML(opts.FontFamily), num(opts.FontSize))
+
+	if opts.Frame {
+		writeFrame(bw, totalW, totalH, opts)
+	} else {
+		fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(opts.Background))
+	}
+
+	fmt.Fprintf(bw, `<g transform="translate(%s %s)">`+"\n", num(offsetX), num(offsetY))
+	for y := 0; y < buf.Height(); y++ {
+		writeBackgrounds(bw, buf, y, cellW, cellH, opts)
+	}
+	for y := 0; y < buf.Height(); y++ {
+		writeText(bw, buf, y, cellW, cellH, opts)
+	}
+	bw.WriteString("</g>\n</svg>\n")
+
+	return bw.Flush()
+}
+
+// withSVGDefaults fills zero fields from DefaultSVGOptions
+func withSVGDefaults(opts SVGOptions) SVGOptions {
+	def := DefaultSVGOptions()
+	if opts.FontFamily == "" {
+		opts.FontFamily = def.FontFamily
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = def.FontSize
+	}
+	if opts.LineHeight <= 0 {
+		opts.LineHeight = def.LineHeight
+	}
+	if opts.Foreground == nil {
+		opts.Foreground = def.Foreground
+	}
+	if opts.Background == nil {
+		opts.Background = def.Background
+	}
+	return opts
+}
+
+// writeFrame draws the window background, title bar and buttons
+func writeFrame(w *bufio.Writer, width, height float64, opts SVGOptions) {
+	fmt.Fprintf(w, `<rect width="%s" height="%s" rx="%d" fill="%s"/>`+"\n",
+		num(width), num(height), frameRadius, hexColor(opts.Background))
+
+	for i, fill := range []string{"#ff5f58", "#ffbd2e", "#18c132"} {
+		fmt.Fprintf(w, `<circle cx="%d" cy="%d" r="6" fill="%s"/>`+"\n", 18+i*20, frameBarHeight/2+2, fill)
+	}
+
+	if opts.Title != "" {
+		fmt.Fprintf(w, `<text x="%s" y="%d" fill="%s" text-anchor="middle" opacity="0.7">%s</text>`+"\n",
+			num(width/2), frameBarHeight/2-int(opts.FontSize/2)+2, hexColor(opts.Foreground), escapeXML(opts.Title))
+	}
+}
+
+// cellColors resolves the effective foreground and background of a cell,
+// applying reverse video. A nil background means the default is visible.
+func cellColors(c *cellbuf.Cell, defaultFg, defaultBg color.Color) (fg, bg color.Color) {
+	fg, bg = c.Style.Fg, c.Style.Bg
+	if c.Style.Attrs&cellbuf.ReverseAttr != 0 {
+		fg, bg = bg, fg
+		if bg == nil {
+			bg = defaultFg
+		}
+		if fg == nil {
+			fg = defaultBg
+		}
+	}
+	if fg == nil {
+		fg = defaultFg
+	}
+	return fg, bg
+}
+
+// writeBackgrounds draws one rectangle per run of cells sharing a background
+func writeBackgrounds(w *bufio.Writer, buf *cellbuf.Buffer, y int, cellW, cellH float64, opts SVGOptions) {
+	runStart := 0
+	var runColor string
+
+	flush := func(end int) {
+		if runColor != "" && end > runStart {
+			fmt.Fprintf(w, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+				num(float64(runStart)*cellW), num(float64(y)*cellH),
+				num(float64(end-runStart)*cellW), num(cellH), runColor)
+		}
+	}
+
+	for x := 0; x < buf.Width(); x++ {
+		c := buf.Cell(x, y)
+		if c == nil || c.Width == 0 {
+			// Wide character placeholders share the background of their cell
+			continue
+		}
+
+		var hex string
+		if _, bg := cellColors(c, opts.Foreground, opts.Background); bg != nil {
+			hex = hexColor(bg)
+		}
+		if hex != runColor {
+			flush(x)
+			runStart, runColor = x, hex
+		}
+	}
+	flush(buf.Width())
+}
+
+// textRun is a sequence of narrow cells that share a style
+type textRun struct {
+	start int
+	text  strings.Builder
+	attrs string
+}
+
+// writeText draws the characters of a row, grouping narrow cells with the
+// same style into a single element. Wide characters are positioned on their
+// own so that the grid stays aligned whatever the font's glyph widths are.
+func writeText(w *bufio.Writer, buf *cellbuf.Buffer, y int, cellW, cellH float64, opts SVGOptions) {
+	var run *textRun
+
+	flush := func() {
+		if run != nil && strings.TrimSpace(run.text.String()) != "" {
+			text := run.text.String()
+			if !strings.Contains(run.attrs, "text-decoration") {
+				// Trailing spaces are invisible unless they are decorated
+				text = strings.TrimRight(text, " ")
+			}
+			fmt.Fprintf(w, `<text x="%s" y="%s"%s>%s</text>`+"\n",
+				num(float64(run.start)*cellW), num(float64(y)*cellH), run.attrs, escapeXML(text))
+		}
+		run = nil
+	}
+
+	for x := 0; x < buf.Width(); x++ {
+		c := buf.Cell(x, y)
+		if c == nil || c.Width == 0 {
+			continue
+		}
+
+		attrs := textAttrs(c, opts)
+		if c.Width > 1 {
+			flush()
+			fmt.Fprintf(w, `<text x="%s" y="%s" textLength="%s" lengthAdjust="spacingAndGlyphs"%s>%s</text>`+"\n",
+				num(float64(x)*cellW), num(float64(y)*cellH), num(float64(c.Width)*cellW), attrs, escapeXML(c.String()))
+			continue
+		}
+
+		if run == nil || run.attrs != attrs {
+			flush()
+			run = &textRun{start: x, attrs: attrs}
+		}
+		if c.Rune == 0 {
+			run.text.WriteByte(' ')
+		} else {
+			run.text.WriteString(c.String())
+		}
+	}
+	flush()
+}
+
+// textAttrs returns the SVG attributes for a cell's foreground style
+func textAttrs(c *cellbuf.Cell, opts SVGOptions) string {
+	var b strings.Builder
+	fg, _ := cellColors(c, opts.Foreground, opts.Background)
+	fmt.Fprintf(&b, ` fill="%s"`, hexColor(fg))
+
+	attrs := c.Style.Attrs
+	if attrs&cellbuf.BoldAttr != 0 {
+		b.WriteString(` font-weight="bold"`)
+	}
+	if attrs&cellbuf.ItalicAttr != 0 {
+		b.WriteString(` font-style="italic"`)
+	}
+	if attrs&cellbuf.FaintAttr != 0 {
+		b.WriteString(` opacity="0.5"`)
+	}
+	if attrs&cellbuf.ConcealAttr != 0 {
+		b.WriteString(` fill-opacity="0"`)
+	}
+
+	var decorations []string
+	if c.Style.UlStyle != cellbuf.NoUnderline {
+		decorations = append(decorations, "underline")
+	}
+	if attrs&cellbuf.StrikethroughAttr != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		fmt.Fprintf(&b, ` text-decoration="%s"`, strings.Join(decorations, " "))
+	}
+
+	return b.String()
+}
+
+// hexColor formats a colour as a CSS hex string
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// num formats a length without trailing zeros
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#39;",
+)
+
+// escapeXML escapes text for use in element content and attributes
+func escapeXML(s string) string {
+	return xmlEscaper.Replace(s)
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// golden compares got with a file in testdata, or rewrites the file when the
+// tests are run with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file, got:\n%s", name, got)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	view := "\x1b[1;38;2;255;95;135mBold\x1b[0m \x1b[48;5;62m bg \x1b[0m\n" +
+		"日本 wide\n" +
+		"<a & \"b\" 'c'>\n" +
+		"\x1b[7mreverse\x1b[0m \x1b[4munder \x1b[0m"
+
+	opts := DefaultSVGOptions()
+	opts.Title = "Tags <&>"
+
+	var buf bytes.Buffer
+	if err := SVG(&buf, view, 20, 4, opts); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "view.svg", buf.Bytes())
+}
+
+func TestSVGWithoutFrame(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SVG(&buf, "plain", 5, 1, SVGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "plain.svg", buf.Bytes())
+}
@@ -0,0 +1,43 @@
+package bubblebook
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/export"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// styled is a story drawn in a colour.
+type styled struct{}
+
+func (styled) Init() tea.Cmd                       { return nil }
+func (styled) Update(tea.Msg) (tea.Model, tea.Cmd) { return styled{}, nil }
+func (styled) View() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Render("Red")
+}
+
+func TestExportKeepsColorProfile(t *testing.T) {
+	withStories(t, models.ComponentEntry{
+		Name:    "Red",
+		Factory: func() tea.Model { return styled{} },
+	})
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	defer lipgloss.SetColorProfile(previous)
+
+	var buf bytes.Buffer
+	if err := ExportSVG(&buf, "Red", 10, 1, export.DefaultSVGOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `fill="#ff0000"`) {
+		t.Error("the story was not rendered in colour")
+	}
+	if got := lipgloss.ColorProfile(); got != termenv.Ascii {
+		t.Errorf("the colour profile is %v after exporting, want it restored to Ascii", got)
+	}
+}
@@ -0,0 +1,70 @@
+package headless
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/lint"
+)
+
+// Render sizes a model and returns its view without starting a program.
+// Commands returned by the model are discarded, and a panic inside Update or
+// View is reported as an error.
+func Render(model tea.Model, width, height int) (view string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("component panicked: %v", r)
+		}
+	}()
+
+	model, _ = model.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return model.View(), nil
+}
+
+// Play sizes a model and then sends it each message in turn. frame is
+// called with the view after the resize (step 0) and after every message.
+// Commands returned by the model are discarded, and a panic is reported as
+// an error.
+func Play(model tea.Model, width, height int, msgs []tea.Msg, frame func(step int, view string)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("component panicked: %v", r)
+		}
+	}()
+
+	model, _ = model.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	frame(0, model.View())
+
+	for i, msg := range msgs {
+		model, _ = model.Update(msg)
+		frame(i+1, model.View())
+	}
+	return nil
+}
+
+// Replay sends each message to a model in turn and returns the final view.
+// Commands returned by the model are discarded, and a panic is reported as
+// an error.
+func Replay(model tea.Model, msgs []tea.Msg) (view string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("component panicked: %v", r)
+		}
+	}()
+
+	for _, msg := range msgs {
+		model, _ = model.Update(msg)
+	}
+	return model.View(), nil
+}
+
+// Lint sizes a model, renders it and returns the layout mistakes in its
+// view. Commands returned by the model are discarded, and a panic is
+// reported as an error.
+func Lint(model tea.Model, width, height int) ([]lint.Warning, error) {
+	view, err := Render(model, width, height)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Check(view, width, height), nil
+}
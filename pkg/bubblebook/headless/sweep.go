@@ -0,0 +1,138 @@
+package headless
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/lint"
+)
+
+// SweepOptions is the grid of sizes a sweep renders a model at
+type SweepOptions struct {
+	MinWidth, MaxWidth   int
+	MinHeight, MaxHeight int
+	// WidthStep and HeightStep are the distances between sizes
+	WidthStep, HeightStep int
+}
+
+// DefaultSweepOptions returns widths 20 to 200 in steps of 10 and heights 5
+// to 60 in steps of 5
+func DefaultSweepOptions() SweepOptions {
+	return SweepOptions{
+		MinWidth: 20, MaxWidth: 200,
+		MinHeight: 5, MaxHeight: 60,
+		WidthStep: 10, HeightStep: 5,
+	}
+}
+
+// SizeResult is what went wrong at one size of a sweep
+type SizeResult struct {
+	Width, Height int
+	// Err is set when the model panicked
+	Err error
+	// Overflow is set when the view is wider or taller than the size
+	Overflow bool
+	// Empty is set when the view has no visible text
+	Empty bool
+}
+
+// OK reports whether nothing went wrong
+func (r SizeResult) OK() bool {
+	return r.Err == nil && !r.Overflow && !r.Empty
+}
+
+// Sweep renders a fresh model from factory at every size of the grid, row
+// by row, and reports what went wrong at each
+func Sweep(factory func() tea.Model, opts SweepOptions) []SizeResult {
+	var results []SizeResult
+	for _, height := range steps(opts.MinHeight, opts.MaxHeight, opts.HeightStep) {
+		for _, width := range steps(opts.MinWidth, opts.MaxWidth, opts.WidthStep) {
+			result := SizeResult{Width: width, Height: height}
+			view, err := renderFresh(factory, width, height)
+			if err != nil {
+				result.Err = err
+			} else {
+				for _, w := range lint.Check(view, width, height) {
+					if w.Kind == lint.TooWide || w.Kind == lint.TooTall {
+						result.Overflow = true
+					}
+				}
+				result.Empty = strings.TrimSpace(ansi.Strip(view)) == ""
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// renderFresh creates and initialises a model and renders it at a size,
+// reporting a panic in the factory or Init as an error too. The commands
+// Init returns are not run.
+func renderFresh(factory func() tea.Model, width, height int) (view string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("component panicked: %v", r)
+		}
+	}()
+	model := factory()
+	model.Init()
+	return Render(model, width, height)
+}
+
+// steps returns the values from lo to hi, always including hi
+func steps(lo, hi, step int) []int {
+	step = max(step, 1)
+	var values []int
+	for v := lo; v < hi; v += step {
+		values = append(values, v)
+	}
+	return append(values, hi)
+}
+
+// Breakpoints summarises a sweep as the smallest sizes from which every
+// larger size of the grid works: for each, every result at least as wide
+// and at least as tall is OK. They are ordered by width, and get shorter as
+// they get wider. There are none when the largest size fails.
+func Breakpoints(results []SizeResult) []tea.WindowSizeMsg {
+	widths, heights := map[int]bool{}, map[int]bool{}
+	for _, r := range results {
+		widths[r.Width] = true
+		heights[r.Height] = true
+	}
+
+	hs := slices.Sorted(maps.Keys(heights))
+
+	var breakpoints []tea.WindowSizeMsg
+	lowest := 0
+	for _, width := range slices.Sorted(maps.Keys(widths)) {
+		// Find the lowest height from which everything at least this wide
+		// works, scanning from the top
+		from := 0
+		for i := len(hs) - 1; i >= 0; i-- {
+			if !worksFrom(results, width, hs[i]) {
+				break
+			}
+			from = hs[i]
+		}
+		if from == 0 || (lowest != 0 && from >= lowest) {
+			continue
+		}
+		lowest = from
+		breakpoints = append(breakpoints, tea.WindowSizeMsg{Width: width, Height: from})
+	}
+	return breakpoints
+}
+
+// worksFrom reports whether every result at least the given size is OK
+func worksFrom(results []SizeResult, width, height int) bool {
+	for _, r := range results {
+		if r.Width >= width && r.Height >= height && !r.OK() {
+			return false
+		}
+	}
+	return true
+}
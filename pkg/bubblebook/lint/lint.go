@@ -0,0 +1,191 @@
+// Package lint checks a rendered view for layout mistakes, such as lines
+// wider than the size its component was given.
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// Kind is a kind of layout mistake
+type Kind string
+
+const (
+	// TooWide is a line wider than the component was given
+	TooWide Kind = "too wide"
+	// TooTall is a view with more lines than the component was given
+	TooTall Kind = "too tall"
+	// Tab is a line with a tab, which terminals expand to a width the
+	// layout cannot know
+	Tab Kind = "tab"
+	// CarriageReturn is a line with a carriage return, which moves the
+	// cursor back over what was drawn
+	CarriageReturn Kind = "carriage return"
+	// TrailingSpace is a line ending in unstyled whitespace. Blank lines,
+	// and the padding of a block with a border down its left side, as
+	// lipgloss adds to reach a width, are left out.
+	TrailingSpace Kind = "trailing space"
+	// UnresetStyle is a line whose style carries over to the next one
+	UnresetStyle Kind = "style not reset"
+)
+
+// Warning reports one kind of mistake and the lines it was found on
+type Warning struct {
+	Kind Kind
+	// Lines are the numbers of the offending lines, counting from 1
+	Lines []int
+	// Detail describes the worst case, such as "92 cells, 80 allowed"
+	Detail string
+}
+
+// String describes the warning on one line
+func (w Warning) String() string {
+	var b strings.Builder
+	b.WriteString(string(w.Kind))
+	if w.Detail != "" {
+		fmt.Fprintf(&b, " (%s)", w.Detail)
+	}
+
+	const shown = 5
+	lines := make([]string, 0, shown)
+	for _, line := range w.Lines[:min(len(w.Lines), shown)] {
+		lines = append(lines, fmt.Sprint(line))
+	}
+	if len(w.Lines) > shown {
+		lines = append(lines, "…")
+	}
+	if len(w.Lines) == 1 {
+		fmt.Fprintf(&b, ": line %s", lines[0])
+	} else if len(w.Lines) > 1 {
+		fmt.Fprintf(&b, ": lines %s", strings.Join(lines, ", "))
+	}
+	return b.String()
+}
+
+// Check returns the layout mistakes in a view rendered for the given size,
+// one warning per kind. A width or height of zero or less is not checked.
+func Check(view string, width, height int) []Warning {
+	lines := strings.Split(view, "\n")
+	found := make(map[Kind]*Warning)
+	var order []Kind
+	flag := func(kind Kind, line int) *Warning {
+		w, ok := found[kind]
+		if !ok {
+			w = &Warning{Kind: kind}
+			found[kind] = w
+			order = append(order, kind)
+		}
+		if line > 0 {
+			w.Lines = append(w.Lines, line)
+		}
+		return w
+	}
+
+	widths := make([]int, len(lines))
+	plain := make([]string, len(lines))
+	for i, line := range lines {
+		widths[i] = ansi.StringWidth(line)
+		plain[i] = ansi.Strip(line)
+	}
+
+	widest := 0
+	var pen cellbuf.Style
+	for i, line := range lines {
+		n := i + 1
+
+		if width > 0 && widths[i] > width {
+			flag(TooWide, n)
+			widest = max(widest, widths[i])
+		}
+		if strings.Contains(line, "\t") {
+			flag(Tab, n)
+		}
+		if strings.Contains(line, "\r") {
+			flag(CarriageReturn, n)
+		}
+
+		trailing := false
+		pen, trailing = scanLine(line, pen)
+		if trailing && !isPadding(plain, widths, i) {
+			flag(TrailingSpace, n)
+		}
+		if !pen.Empty() {
+			flag(UnresetStyle, n)
+		}
+	}
+
+	if width > 0 && widest > 0 {
+		found[TooWide].Detail = fmt.Sprintf("%d cells, %d allowed", widest, width)
+	}
+	if height > 0 && len(lines) > height {
+		flag(TooTall, 0).Detail = fmt.Sprintf("%d lines, %d allowed", len(lines), height)
+	}
+
+	warnings := make([]Warning, len(order))
+	for i, kind := range order {
+		warnings[i] = *found[kind]
+	}
+	return warnings
+}
+
+// isPadding reports whether the whitespace a line ends in is padding: the
+// line is blank, or it is part of a bordered block whose lines are padded to
+// the same width.
+func isPadding(plain []string, widths []int, i int) bool {
+	if strings.TrimSpace(plain[i]) == "" {
+		return true
+	}
+	col, ok := leftBorder(plain[i])
+	if !ok {
+		return false
+	}
+	for _, j := range []int{i - 1, i + 1} {
+		if j < 0 || j >= len(plain) || widths[j] != widths[i] {
+			continue
+		}
+		if c, ok := leftBorder(plain[j]); ok && c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// leftBorder returns the column of the box-drawing character a line starts
+// with after its indentation, if it starts with one.
+func leftBorder(plain string) (int, bool) {
+	text := strings.TrimLeft(plain, " ")
+	r, _ := utf8.DecodeRuneInString(text)
+	if r < 0x2500 || r > 0x257f {
+		return 0, false
+	}
+	return len(plain) - len(text), true
+}
+
+// scanLine follows the SGR sequences of a line, starting from the style the
+// previous line left behind. It returns the style at the end of the line,
+// and whether the line ends in whitespace drawn without any style.
+func scanLine(line string, pen cellbuf.Style) (cellbuf.Style, bool) {
+	p := ansi.GetParser()
+	defer ansi.PutParser(p)
+
+	trailing := false
+	var state byte
+	for len(line) > 0 {
+		seq, width, n, newState := ansi.DecodeSequence(line, state, p)
+		switch {
+		case width > 0:
+			trailing = strings.TrimSpace(seq) == "" && pen.Empty()
+		case seq == "\t":
+			trailing = true
+		case ansi.HasCsiPrefix(seq) && p.Command() == 'm':
+			cellbuf.ReadStyle(p.Params(), &pen)
+		}
+		state = newState
+		line = line[n:]
+	}
+	return pen, trailing
+}
@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrailingSpace(t *testing.T) {
+	tests := []struct {
+		name string
+		view string
+		want []int
+	}{
+		{
+			name: "stray space",
+			view: "Title \nbody",
+			want: []int{1},
+		},
+		{
+			name: "stray space on a line as wide as the next",
+			view: "ab \nabc",
+			want: []int{1},
+		},
+		{
+			name: "padded block without a border",
+			view: strings.Join([]string{
+				"",
+				"  Fruits   ",
+				"> 1. Apple ",
+				"  2. Banana",
+				"",
+				"(Use arrow keys)",
+			}, "\n"),
+			want: []int{2, 3},
+		},
+		{
+			name: "padded block with a left border",
+			view: strings.Join([]string{
+				"┃ Fruits   ",
+				"┃ > Apple  ",
+				"┃   Banana ",
+				"",
+				"(Use arrow keys)",
+			}, "\n"),
+		},
+		{
+			name: "box padded to a wider line",
+			view: strings.Join([]string{
+				"╭─────╮   ",
+				"│ Box │   ",
+				"╰─────╯   ",
+				"wider line",
+			}, "\n"),
+		},
+		{
+			name: "blank padding",
+			view: "Title\n     \nbody",
+		},
+		{
+			name: "styled padding",
+			view: "\n   > \x1b[38;5;240mType something...      \x1b[0m\n",
+		},
+		{
+			name: "padding with a background",
+			view: "\x1b[48;5;62m Fruits \x1b[0m\nbody",
+		},
+		{
+			name: "space after a reset",
+			view: "\x1b[1mbold\x1b[0m  \nbody",
+			want: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, w := range Check(tt.view, 0, 0) {
+			if w.Kind == TrailingSpace {
+				got = w.Lines
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: trailing space on lines %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
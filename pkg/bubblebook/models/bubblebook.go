@@ -1,8 +1,14 @@
 package models
 
 import (
+	"fmt"
+	"image/color"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/console"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/trace"
 )
 
 // Pane represents which pane is currently focused
@@ -11,12 +17,31 @@ type Pane int
 const (
 	PaneList Pane = iota
 	PanePreview
+	PaneInspector
+	PaneCompare
+	PaneGallery
+)
+
+const (
+	// defaultSplitPercent is the share of the preview area given to the
+	// left story in compare mode
+	defaultSplitPercent = 50
+	// splitStep is how far the divider moves per key press
+	splitStep = 10
+	// minSplitPercent and maxSplitPercent keep both stories visible
+	minSplitPercent = 20
+	maxSplitPercent = 80
 )
 
 // ComponentEntry represents a registered component
 type ComponentEntry struct {
 	Name    string
 	Factory func() tea.Model
+	// Presets are messages the story can be sent with the keys 1 to 9
+	Presets []Preset
+	// ConsoleMsgs are commands of the message console, by name, that send
+	// the story custom messages
+	ConsoleMsgs map[string]console.ParseFunc
 }
 
 // BubblebookModel is the main application model
@@ -27,6 +52,7 @@ type BubblebookModel struct {
 
 	// Configuration
 	sidebarWidth int
+	config       Config
 
 	// State
 	components    []ComponentEntry
@@ -34,30 +60,118 @@ type BubblebookModel struct {
 	focusedPane   Pane
 	showHelp      bool
 
+	// Compare mode shows a second story next to the preview
+	comparing    bool
+	compareIndex int
+	// compareActive is set while the compare preview is the one last
+	// focused, so preview keys act on it
+	compareActive bool
+	// broadcast sends every key to both stories
+	broadcast    bool
+	splitPercent int
+
+	// restoreGallery opens the gallery when the program starts
+	restoreGallery bool
+
+	// isolating shows the active story alone, at the size of the terminal
+	isolating bool
+
+	// showPresets lists the active story's presets and binds them to keys
+	showPresets bool
+
 	// Sub-models
 	componentList *ComponentListModel
 	preview       *PreviewModel
+	compare       *PreviewModel
+	gallery       *GalleryModel
+	inspector     *InspectorModel
+	// console sends typed messages to the active story
+	console *messageConsole
+
+	styles *styles
+}
+
+// Config holds the settings of the application model
+type Config struct {
+	// HistoryLimit is the number of component states kept for time travel.
+	// Zero or less disables time travel.
+	HistoryLimit int
+	// SlowThreshold is the p95 Update or View duration above which a
+	// component is marked as slow. Zero or less disables the warning.
+	SlowThreshold time.Duration
+	// IsolateKey is the chord that leaves isolated mode, such as "ctrl+]"
+	IsolateKey string
+	// PreviewBackground is the background assumed behind stories that do
+	// not set one, when auditing contrast. Nil uses a dark grey.
+	PreviewBackground color.Color
+	// DisableFiles turns off the tools that write files to the working
+	// directory or read them from it: recordings, traces and profiles.
+	// Books served to remote clients set it.
+	DisableFiles bool
+	// Renderer draws the book's own panels. Books served to remote clients
+	// give every session one set up for the client's terminal. Nil uses
+	// lipgloss's default renderer.
+	Renderer *lipgloss.Renderer
+}
+
+// DefaultConfig returns the settings used by NewBubblebookModel
+func DefaultConfig() Config {
+	return Config{
+		HistoryLimit:  defaultHistoryLimit,
+		SlowThreshold: defaultSlowThreshold,
+		IsolateKey:    defaultIsolateKey,
+	}
 }
 
 // NewBubblebookModel creates a new instance of the main application model
 func NewBubblebookModel(components []ComponentEntry) BubblebookModel {
+	return NewBubblebookModelWithConfig(components, DefaultConfig())
+}
+
+// NewBubblebookModelWithConfig creates a new instance of the main
+// application model with the given settings
+func NewBubblebookModelWithConfig(components []ComponentEntry, config Config) BubblebookModel {
+	st := newStyles(config.Renderer)
+
+	componentList := NewComponentListModel(components)
+	componentList.styles = st
+	gallery := NewGalleryModel()
+	gallery.styles = st
+	inspector := NewInspectorModel()
+	inspector.styles = st
+
 	return BubblebookModel{
 		sidebarWidth:  30,
+		config:        config,
 		components:    components,
 		selectedIndex: 0,
 		focusedPane:   PaneList,
-		componentList: NewComponentListModel(components),
-		preview:       NewPreviewModel(),
+		splitPercent:  defaultSplitPercent,
+		componentList: componentList,
+		preview:       newPreview(config, st),
+		compare:       newPreview(config, st),
+		gallery:       gallery,
+		inspector:     inspector,
+		console:       &messageConsole{},
+		styles:        st,
 	}
 }
 
+// newPreview creates a preview with the given settings and styles
+func newPreview(config Config, st *styles) *PreviewModel {
+	preview := NewPreviewModel()
+	preview.styles = st
+	preview.SetHistoryLimit(config.HistoryLimit)
+	preview.SetSlowThreshold(config.SlowThreshold)
+	preview.SetBackground(config.PreviewBackground)
+	preview.SetFilesDisabled(config.DisableFiles)
+	return preview
+}
+
 // Init initializes the model
 func (m BubblebookModel) Init() tea.Cmd {
-	// Load the first component if available
-	if len(m.components) > 0 {
-		return m.loadComponent(0)
-	}
-	return nil
+	// Load the selected component, and any restored with the session
+	return m.startStories()
 }
 
 // Update handles messages
@@ -65,27 +179,69 @@ func (m BubblebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	// Keep the inspector and the slow and layout badges in step with
+	// whatever this message changed
+	defer func() {
+		active, _ := m.activePreview()
+		m.inspector.Observe(active.InspectedModel())
+		m.preview.SetPrompt("")
+		m.compare.SetPrompt("")
+		if m.console.open {
+			active.SetPrompt(m.console.View(m.styles, active.width-4))
+		}
+		m.lint(m.preview)
+		m.componentList.SetSlow(m.selectedIndex, m.preview.IsSlow())
+		m.componentList.SetLayoutWarnings(m.selectedIndex, m.preview.LayoutWarnings())
+		if m.comparing {
+			m.lint(m.compare)
+			slow := m.compare.IsSlow() || (m.compareIndex == m.selectedIndex && m.preview.IsSlow())
+			m.componentList.SetSlow(m.compareIndex, slow)
+			if m.compareIndex != m.selectedIndex {
+				m.componentList.SetLayoutWarnings(m.compareIndex, m.compare.LayoutWarnings())
+			}
+		}
+	}()
+
+	// In isolated mode the story gets the terminal to itself
+	if m.isolating {
+		switch msg := msg.(type) {
+		case tea.WindowSizeMsg:
+			m.width = msg.Width
+			m.height = msg.Height
+			preview, _ := m.activePreview()
+			return m, preview.ForwardMessage(msg)
+
+		case tea.KeyMsg, tea.MouseMsg:
+			if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == m.isolateKey() {
+				// Back to the book, at the size of the preview pane
+				m.isolating = false
+				return m, m.layout()
+			}
+			preview, _ := m.activePreview()
+			return m, preview.ForwardMessage(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
-		// Update component list size
-		m.componentList.SetSize(m.sidebarWidth-2, m.height-2)
-
-		// Update preview size
-		previewWidth := m.width - m.sidebarWidth
-		m.preview.SetSize(previewWidth-2, m.height-2)
+		// Resize the panes, which also resizes the active component
+		cmd = m.layout()
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
-		// Forward resize to active component if any
-		if m.preview.HasComponent() {
-			cmd = m.preview.ForwardMessage(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
+	case tea.KeyMsg:
+		// The console takes every key but ctrl+c while it is open
+		if m.console.open && !m.showHelp && msg.String() != "ctrl+c" {
+			if line, submit := m.console.update(msg); submit {
+				return m, m.sendFromConsole(line)
 			}
+			return m, nil
 		}
 
-	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
 			// Don't quit if help is showing, just close help
@@ -105,14 +261,103 @@ func (m BubblebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.showHelp {
 				return m, nil
 			}
-			// Toggle focus between panes
-			if m.focusedPane == PaneList {
-				m.focusedPane = PanePreview
-			} else {
-				m.focusedPane = PaneList
+			// Cycle focus through the visible panes
+			switch m.focusedPane {
+			case PaneList:
+				if m.gallery.Visible() {
+					cmd = m.setFocus(PaneGallery)
+				} else {
+					cmd = m.setFocus(PanePreview)
+				}
+			case PanePreview:
+				if m.comparing {
+					cmd = m.setFocus(PaneCompare)
+				} else if m.inspector.Visible() {
+					cmd = m.setFocus(PaneInspector)
+				} else {
+					cmd = m.setFocus(PaneList)
+				}
+			case PaneCompare, PaneGallery:
+				if m.inspector.Visible() {
+					cmd = m.setFocus(PaneInspector)
+				} else {
+					cmd = m.setFocus(PaneList)
+				}
+			default:
+				cmd = m.setFocus(PaneList)
+			}
+			cmds = append(cmds, cmd)
+
+		case "f2":
+			// Toggle recording of the focused component
+			if m.showHelp || !m.previewFocused() {
+				return m, nil
+			}
+			preview, _ := m.activePreview()
+			preview.ToggleRecording()
+			return m, nil
+
+		case "f3":
+			// Restart the component with tracing, or save the trace
+			if m.showHelp || !m.previewFocused() {
+				return m, nil
+			}
+			preview, index := m.activePreview()
+			if preview.IsTracing() {
+				preview.StopTrace()
+				return m, nil
+			}
+			return m, m.loadComponentTraced(preview, index)
+
+		case "f4":
+			// Replay the latest trace of the selected component
+			if m.showHelp || m.gallery.Visible() {
+				return m, nil
+			}
+			return m, m.replayLatestTrace()
+
+		case "f5":
+			// Browse earlier states of the component
+			if m.showHelp || !m.previewFocused() {
+				return m, nil
+			}
+			preview, _ := m.activePreview()
+			return m, preview.ToggleTimeTravel()
+
+		case "f6":
+			// Show or hide the model inspector
+			if m.showHelp {
+				return m, nil
+			}
+			m.inspector.Toggle()
+			if !m.inspector.Visible() && m.focusedPane == PaneInspector {
+				cmds = append(cmds, m.setFocus(PaneList))
 			}
-			m.componentList.SetFocused(m.focusedPane == PaneList)
-			m.preview.SetFocused(m.focusedPane == PanePreview)
+			return m, tea.Batch(append(cmds, m.layout())...)
+
+		case "f7":
+			// Show the profiler, or hide it and export its timings
+			preview, _ := m.activePreview()
+			if m.showHelp || m.gallery.Visible() || !preview.HasComponent() {
+				return m, nil
+			}
+			preview.ToggleProfiler()
+			return m, nil
+
+		case "f8":
+			// Highlight cells that change between renders
+			if m.showHelp || m.gallery.Visible() {
+				return m, nil
+			}
+			preview, _ := m.activePreview()
+			return m, preview.ToggleDiff()
+
+		case "f9":
+			// Open the selected story next to the preview, or close it
+			if m.showHelp || m.gallery.Visible() {
+				return m, nil
+			}
+			return m, m.toggleCompare()
 
 		case "esc":
 			// If help is showing, close it
@@ -121,15 +366,112 @@ func (m BubblebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			// Always return to list
-			m.focusedPane = PaneList
-			m.componentList.SetFocused(true)
-			m.preview.SetFocused(false)
+			cmds = append(cmds, m.setFocus(PaneList))
 
 		default:
 			// Don't route messages if help is showing
 			if m.showHelp {
 				return m, nil
 			}
+			// Show every story of the selected group
+			if m.focusedPane == PaneList && msg.String() == "v" {
+				return m, m.toggleGallery()
+			}
+
+			// Run the active story alone at the size of the terminal
+			if m.focusedPane == PaneList && msg.String() == "i" {
+				return m, m.isolate()
+			}
+
+			// Flag text with too little contrast in the active preview
+			if m.focusedPane == PaneList && msg.String() == "a" && !m.gallery.Visible() {
+				preview, _ := m.activePreview()
+				preview.ToggleContrast()
+				return m, nil
+			}
+
+			// Draw ambiguous-width characters as CJK terminals do
+			if m.focusedPane == PaneList && msg.String() == "w" && !m.gallery.Visible() {
+				preview, _ := m.activePreview()
+				preview.ToggleWideSimulation()
+				return m, nil
+			}
+
+			// Paste text that is hard to measure into the active story
+			if m.focusedPane == PaneList && msg.String() == "t" && !m.gallery.Visible() {
+				preview, _ := m.activePreview()
+				return m, preview.PasteStressText()
+			}
+
+			// Hold the commands the active story returns, to run by hand
+			if m.focusedPane == PaneList && msg.String() == "c" && !m.gallery.Visible() {
+				preview, _ := m.activePreview()
+				return m, preview.ToggleIntercept()
+			}
+
+			// Act on the selected held command
+			if preview, index := m.activePreview(); m.focusedPane == PaneList && !m.gallery.Visible() && preview.HeldCommands() > 0 {
+				switch msg.String() {
+				case "r":
+					return m, preview.RunHeld()
+				case "d":
+					return m, preview.DelayHeld()
+				case "x":
+					preview.DropHeld()
+					return m, nil
+				case "n":
+					preview.SelectNextHeld()
+					return m, nil
+				}
+				// Number keys resolve it with one of the story's presets
+				// instead of sending the preset
+				presets := m.presets(index)
+				if i, ok := presetIndex(msg.String(), presets); ok {
+					return m, preview.ResolveHeld(presets[i].Msgs, fmt.Sprintf("preset %d: %s", i+1, presets[i].Label))
+				}
+			}
+
+			// Send one of the active story's presets
+			if m.showPresets && !m.gallery.Visible() && (m.focusedPane == PaneList || m.previewFocused()) {
+				if cmd, ok := m.sendPreset(msg.String()); ok {
+					return m, cmd
+				}
+			}
+
+			// Show or hide the active story's presets
+			if m.focusedPane == PaneList && msg.String() == "p" && !m.gallery.Visible() {
+				m.showPresets = !m.showPresets
+				return m, nil
+			}
+
+			// Type messages to send to the active story
+			if m.focusedPane == PaneList && msg.String() == ":" && !m.gallery.Visible() {
+				_, index := m.activePreview()
+				m.console.show(m.consoleMsgs(index))
+				return m, nil
+			}
+
+			// Animate the size of the active story to watch it reflow
+			if m.focusedPane == PaneList && msg.String() == "s" && !m.gallery.Visible() {
+				preview, _ := m.activePreview()
+				return m, preview.ToggleSweep()
+			}
+
+			// Compare mode controls
+			if m.focusedPane == PaneList && m.comparing {
+				switch msg.String() {
+				case "b":
+					m.broadcast = !m.broadcast
+					m.preview.SetBroadcast(m.broadcast)
+					m.compare.SetBroadcast(m.broadcast)
+					return m, nil
+				case "[":
+					return m, m.moveSplit(-splitStep)
+				case "]":
+					return m, m.moveSplit(splitStep)
+				}
+			}
+
 			// Route message based on focused pane
 			if m.focusedPane == PaneList {
 				var listCmd tea.Cmd
@@ -145,16 +487,94 @@ func (m BubblebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if cmd != nil {
 						cmds = append(cmds, cmd)
 					}
+
+					// The gallery follows the selection to other groups
+					group := StoryGroup(m.components[m.selectedIndex].Name)
+					if m.gallery.Visible() && group != m.gallery.Group() {
+						cmd = m.gallery.Open(group, m.components, m.selectedIndex)
+						if cmd != nil {
+							cmds = append(cmds, cmd)
+						}
+					}
 				}
-			} else if m.focusedPane == PanePreview {
+			} else if m.previewFocused() {
 				// Forward to preview (which forwards to active component)
-				cmd = m.preview.ForwardMessage(msg)
+				preview, _ := m.activePreview()
+				cmd = preview.ForwardMessage(msg)
+				if cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+
+				// Broadcasting sends the key to the other story as well
+				if m.broadcast {
+					other := m.compare
+					if preview == m.compare {
+						other = m.preview
+					}
+					cmd = other.ForwardMessage(msg)
+					if cmd != nil {
+						cmds = append(cmds, cmd)
+					}
+				}
+			} else if m.focusedPane == PaneGallery {
+				if index, open := m.gallery.Update(msg); open {
+					cmd = m.openFromGallery(index)
+					if cmd != nil {
+						cmds = append(cmds, cmd)
+					}
+				}
+			} else if m.focusedPane == PaneInspector {
+				*m.inspector, cmd = m.inspector.Update(msg)
 				if cmd != nil {
 					cmds = append(cmds, cmd)
 				}
 			}
 		}
 
+	case diffTickMsg:
+		// Redraw so that diff highlights fade. Runs are unique, so only the
+		// preview that scheduled the tick schedules the next one.
+		cmd = m.preview.advanceDiff(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		cmd = m.compare.advanceDiff(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case sweepTickMsg:
+		// Load ids are unique, so only the preview that started the sweep
+		// accepts the tick
+		cmd = m.preview.advanceSweep(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if m.comparing {
+			cmd = m.compare.advanceSweep(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case componentMsg:
+		// Results of the active components' commands. Load ids are unique,
+		// so only the preview that issued the command accepts the result.
+		cmd = m.preview.handleCommandResult(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if m.comparing {
+			cmd = m.compare.handleCommandResult(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		cmd = m.gallery.handleCommandResult(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	default:
 		// Forward all other messages to preview if it has a component
 		if m.preview.HasComponent() {
@@ -163,6 +583,12 @@ func (m BubblebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, cmd)
 			}
 		}
+		if m.comparing && m.compare.HasComponent() {
+			cmd = m.compare.ForwardMessage(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -176,23 +602,295 @@ func (m BubblebookModel) View() string {
 
 	// If help is showing, render help instead
 	if m.showHelp {
-		return RenderHelp(m.width, m.height)
+		return renderHelp(m.styles, m.width, m.height, m.isolateKey())
+	}
+
+	// In isolated mode the story is drawn without any chrome
+	if m.isolating {
+		preview, _ := m.activePreview()
+		return preview.ComponentView()
 	}
 
 	// Render component list
 	listView := m.componentList.View()
 
-	// Render preview
+	// Render preview, and the story it is compared with
 	previewView := m.preview.View()
+	var compareView string
+	if m.comparing {
+		compareView = m.compare.View()
+	}
+
+	// The presets are drawn in the bottom right corner of the active
+	// preview, inside the border
+	if m.showPresets && !m.gallery.Visible() {
+		_, index := m.activePreview()
+		box := renderPresets(m.styles, m.presets(index))
+		corner := func(view string) string {
+			return overlay(view, box, lipgloss.Width(view)-lipgloss.Width(box)-2, lipgloss.Height(view)-lipgloss.Height(box)-1)
+		}
+		if m.comparing && m.compareActive {
+			compareView = corner(compareView)
+		} else {
+			previewView = corner(previewView)
+		}
+	}
+
+	// The gallery takes the place of the previews
+	if m.gallery.Visible() {
+		previewView = m.gallery.View()
+		compareView = ""
+	}
 
 	// Join horizontally
 	return lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		listView,
 		previewView,
+		compareView,
+		m.inspector.View(),
 	)
 }
 
+// layout sizes the panes to fit the window and returns the command the
+// active component responds to its new size with
+func (m BubblebookModel) layout() tea.Cmd {
+	if m.width == 0 || m.height == 0 {
+		return nil
+	}
+
+	// Update component list size
+	m.componentList.SetSize(m.sidebarWidth-2, m.height-2)
+
+	// The inspector docks to the right of the preview
+	previewWidth := m.width - m.sidebarWidth
+	if m.inspector.Visible() {
+		inspectorWidth := min(50, previewWidth/3)
+		m.inspector.SetSize(inspectorWidth-2, m.height-2)
+		previewWidth -= inspectorWidth
+	}
+
+	// The gallery takes the place of the previews
+	if m.gallery.Visible() {
+		return m.gallery.SetSize(previewWidth-2, m.height-2)
+	}
+
+	// In compare mode the two stories share the preview area
+	if m.comparing {
+		left := previewWidth * m.splitPercent / 100
+		cmd := m.preview.SetSize(left-2, m.height-2)
+		return tea.Batch(cmd, m.compare.SetSize(previewWidth-left-2, m.height-2))
+	}
+
+	// Update preview size
+	return m.preview.SetSize(previewWidth-2, m.height-2)
+}
+
+// setFocus moves the focus to a pane, returning the commands the previewed
+// components respond to gaining or losing it with
+func (m *BubblebookModel) setFocus(pane Pane) tea.Cmd {
+	m.focusedPane = pane
+	m.componentList.SetFocused(pane == PaneList)
+	m.gallery.SetFocused(pane == PaneGallery)
+	m.inspector.SetFocused(pane == PaneInspector)
+
+	// The preview losing the focus hears about it first
+	var cmds []tea.Cmd
+	if pane == PanePreview {
+		cmds = append(cmds, m.compare.SetFocused(false), m.preview.SetFocused(true))
+	} else {
+		cmds = append(cmds, m.preview.SetFocused(false), m.compare.SetFocused(pane == PaneCompare))
+	}
+
+	// Preview keys keep acting on the preview that was focused last
+	switch pane {
+	case PanePreview:
+		m.compareActive = false
+	case PaneCompare:
+		m.compareActive = true
+	}
+	return tea.Batch(cmds...)
+}
+
+// previewFocused returns whether either preview has the focus
+func (m BubblebookModel) previewFocused() bool {
+	return m.focusedPane == PanePreview || m.focusedPane == PaneCompare
+}
+
+// activePreview returns the preview that preview keys act on, together
+// with the index of the story it shows
+func (m BubblebookModel) activePreview() (*PreviewModel, int) {
+	if m.comparing && m.compareActive {
+		return m.compare, m.compareIndex
+	}
+	return m.preview, m.selectedIndex
+}
+
+// toggleCompare opens the selected story in a second preview next to the
+// first, or closes it. Selecting another story afterwards loads it into the
+// first preview, so the two can be compared.
+func (m *BubblebookModel) toggleCompare() tea.Cmd {
+	if m.comparing {
+		m.compare.stopRecordings()
+		m.comparing = false
+		m.compareActive = false
+		m.broadcast = false
+		m.preview.SetBroadcast(false)
+		m.compare = newPreview(m.config, m.styles)
+		var cmd tea.Cmd
+		if m.focusedPane == PaneCompare {
+			cmd = m.setFocus(PanePreview)
+		}
+		return tea.Batch(cmd, m.layout())
+	}
+
+	if m.selectedIndex < 0 || m.selectedIndex >= len(m.components) {
+		return nil
+	}
+	m.comparing = true
+	m.compareIndex = m.selectedIndex
+
+	// Make room for the second preview before its component starts
+	cmd := m.layout()
+	entry := m.components[m.compareIndex]
+	return tea.Batch(cmd, m.compare.LoadComponent(entry.Factory(), entry.Name))
+}
+
+// presets returns the presets of a story
+func (m BubblebookModel) presets(index int) []Preset {
+	if index < 0 || index >= len(m.components) {
+		return nil
+	}
+	return m.components[index].Presets
+}
+
+// consoleMsgs returns the console commands of a story
+func (m BubblebookModel) consoleMsgs(index int) map[string]console.ParseFunc {
+	if index < 0 || index >= len(m.components) {
+		return nil
+	}
+	return m.components[index].ConsoleMsgs
+}
+
+// sendPreset forwards the messages of the active story's preset bound to a
+// key. It reports false when no preset is bound to the key.
+func (m *BubblebookModel) sendPreset(key string) (tea.Cmd, bool) {
+	preview, index := m.activePreview()
+	presets := m.presets(index)
+	i, ok := presetIndex(key, presets)
+	if !ok {
+		return nil, false
+	}
+	if !preview.HasComponent() {
+		return nil, true
+	}
+
+	preset := presets[i]
+	cmds := make([]tea.Cmd, len(preset.Msgs))
+	for j, msg := range preset.Msgs {
+		cmds[j] = preview.ForwardMessage(msg)
+	}
+	preview.SetStatus(fmt.Sprintf("Sent preset %d: %s", i+1, preset.Label))
+	return tea.Batch(cmds...), true
+}
+
+// sendFromConsole sends the messages a console line stands for to the
+// active story, or shows why it cannot
+func (m *BubblebookModel) sendFromConsole(line string) tea.Cmd {
+	_, index := m.activePreview()
+	msgs, err := console.Parse(line, m.consoleMsgs(index))
+	if err != nil {
+		m.console.err = err.Error()
+		return nil
+	}
+
+	preview, _ := m.activePreview()
+	switch {
+	case !preview.HasComponent():
+		m.console.err = "no story to send to"
+		return nil
+	case preview.IsTimeTravelling():
+		m.console.err = "press f5 to leave time travel first"
+		return nil
+	}
+
+	m.console.remember(line)
+	return preview.Inject(msgs, line)
+}
+
+// toggleGallery shows every story in the selected story's group in a grid
+// in place of the preview, or hides the grid
+func (m *BubblebookModel) toggleGallery() tea.Cmd {
+	if m.gallery.Visible() {
+		m.gallery.Close()
+		return m.layout()
+	}
+
+	if m.selectedIndex < 0 || m.selectedIndex >= len(m.components) {
+		return nil
+	}
+
+	// The gallery replaces compare mode
+	var cmds []tea.Cmd
+	if m.comparing {
+		cmds = append(cmds, m.toggleCompare())
+	}
+
+	group := StoryGroup(m.components[m.selectedIndex].Name)
+	cmds = append(cmds, m.gallery.Open(group, m.components, m.selectedIndex))
+	cmds = append(cmds, m.layout())
+	cmds = append(cmds, m.setFocus(PaneGallery))
+	return tea.Batch(cmds...)
+}
+
+// openFromGallery closes the gallery and shows a story in the preview
+func (m *BubblebookModel) openFromGallery(index int) tea.Cmd {
+	m.gallery.Close()
+	m.componentList.Select(index)
+	m.selectedIndex = index
+	focus := m.setFocus(PanePreview)
+
+	// Resize the preview back before the story starts in it
+	cmd := m.layout()
+	return tea.Batch(focus, cmd, m.loadComponent(index))
+}
+
+// isolate shows the active story alone and sends it the size of the whole
+// terminal. The isolate key returns to the book.
+func (m *BubblebookModel) isolate() tea.Cmd {
+	preview, _ := m.activePreview()
+	if m.gallery.Visible() || !preview.HasComponent() {
+		return nil
+	}
+
+	m.isolating = true
+	return preview.ForwardMessage(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+}
+
+// lint checks the layout of a preview's story against the size it was
+// given, which is the whole terminal while it is isolated
+func (m *BubblebookModel) lint(preview *PreviewModel) {
+	if active, _ := m.activePreview(); m.isolating && preview == active {
+		preview.LintAt(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return
+	}
+	preview.Lint()
+}
+
+// isolateKey returns the chord that leaves isolated mode
+func (m BubblebookModel) isolateKey() string {
+	if m.config.IsolateKey == "" {
+		return defaultIsolateKey
+	}
+	return m.config.IsolateKey
+}
+
+// moveSplit moves the divider between the compared stories
+func (m *BubblebookModel) moveSplit(delta int) tea.Cmd {
+	m.splitPercent = min(max(m.splitPercent+delta, minSplitPercent), maxSplitPercent)
+	return m.layout()
+}
+
 // loadComponent loads a component by index
 func (m BubblebookModel) loadComponent(index int) tea.Cmd {
 	if index < 0 || index >= len(m.components) {
@@ -205,3 +903,46 @@ func (m BubblebookModel) loadComponent(index int) tea.Cmd {
 	// Set the component in the preview
 	return m.preview.LoadComponent(component, entry.Name)
 }
+
+// loadComponentTraced reloads a component by index into a preview with
+// tracing enabled
+func (m BubblebookModel) loadComponentTraced(preview *PreviewModel, index int) tea.Cmd {
+	if index < 0 || index >= len(m.components) {
+		return nil
+	}
+
+	entry := m.components[index]
+	return preview.LoadComponentTraced(entry.Factory(), entry.Name)
+}
+
+// replayLatestTrace replays the newest trace file saved for the component
+// in the active preview, returning the commands it responds with
+func (m BubblebookModel) replayLatestTrace() tea.Cmd {
+	preview, index := m.activePreview()
+	if index < 0 || index >= len(m.components) {
+		return nil
+	}
+	entry := m.components[index]
+	if m.config.DisableFiles {
+		preview.SetStatus(filesDisabledStatus)
+		return nil
+	}
+
+	path, err := latestTrace(entry.Name)
+	if err != nil {
+		preview.SetStatus(err.Error())
+		return nil
+	}
+
+	t, err := trace.Load(path)
+	if err != nil {
+		preview.SetStatus(err.Error())
+		return nil
+	}
+	if t.Story != entry.Name {
+		preview.SetStatus(fmt.Sprintf("%s was recorded for %q", path, t.Story))
+		return nil
+	}
+
+	return preview.Replay(entry.Factory(), t, path)
+}
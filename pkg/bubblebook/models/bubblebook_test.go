@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filler is a story that fills exactly the size it was given.
+type filler struct {
+	width, height int
+}
+
+func (f filler) Init() tea.Cmd { return nil }
+
+func (f filler) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		f.width, f.height = msg.Width, msg.Height
+	}
+	return f, nil
+}
+
+func (f filler) View() string {
+	line := strings.Repeat("x", f.width)
+	lines := make([]string, f.height)
+	for i := range lines {
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// send passes messages to the book in turn.
+func send(m BubblebookModel, msgs ...tea.Msg) BubblebookModel {
+	for _, msg := range msgs {
+		model, _ := m.Update(msg)
+		m = model.(BubblebookModel)
+	}
+	return m
+}
+
+func TestIsolatedLintUsesTerminalSize(t *testing.T) {
+	m := NewBubblebookModel([]ComponentEntry{
+		{Name: "Filler", Factory: func() tea.Model { return filler{} }},
+	})
+	m = send(m, tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.Init()
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if !m.isolating {
+		t.Fatal("i did not isolate the story")
+	}
+	if got := m.preview.ComponentView(); !strings.HasPrefix(got, strings.Repeat("x", 100)) {
+		t.Fatalf("the isolated story was not given the terminal's width:\n%s", got)
+	}
+	if n := m.preview.LayoutWarnings(); n != 0 {
+		t.Errorf("a story filling the terminal has %d layout warnings while isolated: %s", n, m.preview.linter.View(defaultStyles))
+	}
+}
+
+func TestHelpShowsIsolateKey(t *testing.T) {
+	config := DefaultConfig()
+	config.IsolateKey = "ctrl+x"
+	m := NewBubblebookModelWithConfig(nil, config)
+	m = send(m, tea.WindowSizeMsg{Width: 200, Height: 80})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+
+	view := m.View()
+	if !strings.Contains(view, "ctrl+x returns") {
+		t.Errorf("the help does not name the configured isolate key:\n%s", view)
+	}
+	if strings.Contains(view, "ctrl+] returns") {
+		t.Error("the help names the default isolate key")
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// componentMsg carries the result of a command returned by a component, so
+// it can be routed back to the instance that issued it
+type componentMsg struct {
+	// id identifies the component load the command belongs to
+	id  int
+	msg tea.Msg
+	// held marks the result of a command that was intercepted and then run
+	held bool
+}
+
+var (
+	cmdType     = reflect.TypeOf(tea.Cmd(nil))
+	teaPkgPath  = reflect.TypeOf(tea.KeyMsg{}).PkgPath()
+	teaInputMsg = map[reflect.Type]bool{
+		reflect.TypeOf(tea.KeyMsg{}):        true,
+		reflect.TypeOf(tea.MouseMsg{}):      true,
+		reflect.TypeOf(tea.WindowSizeMsg{}): true,
+		reflect.TypeOf(tea.FocusMsg{}):      true,
+		reflect.TypeOf(tea.BlurMsg{}):       true,
+	}
+)
+
+// loadCounter numbers component loads across every preview, so a command
+// result can only ever match the preview that issued it. Programs served
+// over SSH share it.
+var loadCounter atomic.Int64
+
+// nextLoadID returns a new load id
+func nextLoadID() int {
+	return int(loadCounter.Add(1))
+}
+
+// tagCmd wraps a command so that its result is delivered as a componentMsg
+func tagCmd(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return tagMsg(id, cmd())
+	}
+}
+
+// tagMsg wraps a command result in a componentMsg. Batches and sequences are
+// run by the program, so their commands are tagged individually instead,
+// and messages meant for the program itself (quitting, screen control and
+// so on) are passed through untouched.
+func tagMsg(id int, msg tea.Msg) tea.Msg {
+	if msg == nil {
+		return nil
+	}
+
+	if cmds, ok := cmdSlice(msg); ok {
+		tagged := reflect.MakeSlice(reflect.TypeOf(msg), len(cmds), len(cmds))
+		for i, cmd := range cmds {
+			tagged.Index(i).Set(reflect.ValueOf(tagCmd(id, cmd)))
+		}
+		return tagged.Interface()
+	}
+
+	if forProgram(msg) {
+		return msg
+	}
+
+	return componentMsg{id: id, msg: msg}
+}
+
+// cmdSlice returns the commands of a batch or sequence, which the program
+// runs itself
+func cmdSlice(msg tea.Msg) ([]tea.Cmd, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || v.Type().Elem() != cmdType {
+		return nil, false
+	}
+	cmds := make([]tea.Cmd, v.Len())
+	for i := range cmds {
+		cmds[i], _ = v.Index(i).Interface().(tea.Cmd)
+	}
+	return cmds, true
+}
+
+// forProgram returns whether a message is meant for the program itself,
+// such as tea.QuitMsg, rather than for the component
+func forProgram(msg tea.Msg) bool {
+	t := reflect.TypeOf(msg)
+	return t.PkgPath() == teaPkgPath && !teaInputMsg[t]
+}
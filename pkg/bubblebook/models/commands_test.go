@@ -0,0 +1,82 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// noteMsg is a message a test command returns.
+type noteMsg string
+
+// note returns a command that results in a noteMsg.
+func note(text string) tea.Cmd {
+	return func() tea.Msg { return noteMsg(text) }
+}
+
+// run runs a command as the program would, running the commands of
+// batches and sequences in turn, and returns the messages that result.
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if cmds, ok := cmdSlice(msg); ok {
+		var msgs []tea.Msg
+		for _, cmd := range cmds {
+			msgs = append(msgs, run(cmd)...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+func TestTagMsg(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.Msg
+		want tea.Msg
+	}{
+		{"nothing", nil, nil},
+		{"component message", noteMsg("hi"), componentMsg{id: 7, msg: noteMsg("hi")}},
+		{"input message", tea.KeyMsg{Type: tea.KeyUp}, componentMsg{id: 7, msg: tea.KeyMsg{Type: tea.KeyUp}}},
+		{"quit", tea.QuitMsg{}, tea.QuitMsg{}},
+	}
+
+	for _, tt := range tests {
+		if got := tagMsg(7, tt.msg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: tagMsg() = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTagCmdBatches(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  tea.Cmd
+	}{
+		{"batch", tea.Batch(note("a"), nil, note("b"))},
+		{"sequence", tea.Sequence(note("a"), note("b"))},
+		{"nested", tea.Batch(note("a"), tea.Sequence(note("b"), nil))},
+	}
+
+	for _, tt := range tests {
+		// The program only runs batches and sequences of its own types, so
+		// they must keep their type when tagged
+		if got, want := reflect.TypeOf(tagCmd(7, tt.cmd)()), reflect.TypeOf(tt.cmd()); got != want {
+			t.Errorf("%s: tagging turned a %v into a %v", tt.name, want, got)
+		}
+
+		want := []tea.Msg{
+			componentMsg{id: 7, msg: noteMsg("a")},
+			componentMsg{id: 7, msg: noteMsg("b")},
+		}
+		if got := run(tagCmd(7, tt.cmd)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the tagged commands result in %#v, want %#v", tt.name, got, want)
+		}
+	}
+}
@@ -1,38 +1,10 @@
 package models
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-)
-
-var (
-	listBorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(0, 1)
-
-	listBorderFocusedStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("205")).
-				Padding(0, 1)
-
-	listTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true)
-
-	selectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("205")).
-				Bold(true).
-				PaddingLeft(2)
-
-	normalItemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("246")).
-			PaddingLeft(2)
-
-	cursorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205"))
 )
 
 // ComponentListModel handles the component list sidebar
@@ -43,6 +15,10 @@ type ComponentListModel struct {
 	height        int
 	focused       bool
 	scrollOffset  int
+	slow          map[int]bool
+	// layout counts the kinds of layout mistakes found in each component
+	layout map[int]int
+	styles *styles
 }
 
 // NewComponentListModel creates a new component list model
@@ -52,6 +28,28 @@ func NewComponentListModel(components []ComponentEntry) *ComponentListModel {
 		selectedIndex: 0,
 		focused:       true,
 		scrollOffset:  0,
+		slow:          make(map[int]bool),
+		layout:        make(map[int]int),
+		styles:        defaultStyles,
+	}
+}
+
+// SetSlow marks a component as slow to update or render
+func (m *ComponentListModel) SetSlow(index int, slow bool) {
+	if slow {
+		m.slow[index] = true
+	} else {
+		delete(m.slow, index)
+	}
+}
+
+// SetLayoutWarnings sets how many kinds of layout mistakes were found in a
+// component's view
+func (m *ComponentListModel) SetLayoutWarnings(index, count int) {
+	if count > 0 {
+		m.layout[index] = count
+	} else {
+		delete(m.layout, index)
 	}
 }
 
@@ -59,6 +57,7 @@ func NewComponentListModel(components []ComponentEntry) *ComponentListModel {
 func (m *ComponentListModel) SetSize(width, height int) {
 	m.width = width
 	m.height = height
+	m.ensureVisible()
 }
 
 // SetFocused sets the focus state
@@ -71,6 +70,26 @@ func (m *ComponentListModel) SelectedIndex() int {
 	return m.selectedIndex
 }
 
+// Select moves the selection to a component
+func (m *ComponentListModel) Select(index int) {
+	if index < 0 || index >= len(m.components) {
+		return
+	}
+	m.selectedIndex = index
+	m.ensureVisible()
+}
+
+// ScrollOffset returns how far the list is scrolled
+func (m *ComponentListModel) ScrollOffset() int {
+	return m.scrollOffset
+}
+
+// SetScrollOffset scrolls the list, keeping the selection visible
+func (m *ComponentListModel) SetScrollOffset(offset int) {
+	m.scrollOffset = min(max(offset, 0), max(len(m.components)-1, 0))
+	m.ensureVisible()
+}
+
 // Update handles messages
 func (m ComponentListModel) Update(msg tea.Msg) (ComponentListModel, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -108,6 +127,10 @@ func (m ComponentListModel) Update(msg tea.Msg) (ComponentListModel, tea.Cmd) {
 
 // ensureVisible adjusts scroll offset to keep selected item visible
 func (m *ComponentListModel) ensureVisible() {
+	if m.height == 0 {
+		// Not laid out yet
+		return
+	}
 	visibleLines := m.height - 4 // Account for border and title
 
 	if m.selectedIndex < m.scrollOffset {
@@ -126,7 +149,7 @@ func (m ComponentListModel) View() string {
 	var b strings.Builder
 
 	// Title
-	title := listTitleStyle.Render("Components")
+	title := m.styles.listTitle.Render("Components")
 	b.WriteString(title)
 	b.WriteString("\n\n")
 
@@ -146,14 +169,20 @@ func (m ComponentListModel) View() string {
 	for i := startIdx; i < endIdx; i++ {
 		component := m.components[i]
 		cursor := "  "
-		style := normalItemStyle
+		style := m.styles.normalItem
 
 		if i == m.selectedIndex {
-			cursor = cursorStyle.Render("▶ ")
-			style = selectedItemStyle
+			cursor = m.styles.cursor.Render("▶ ")
+			style = m.styles.selectedItem
 		}
 
 		line := cursor + style.Render(component.Name)
+		if m.slow[i] {
+			line += m.styles.slowBadge.Render(" ⚠ slow")
+		}
+		if count := m.layout[i]; count > 0 {
+			line += m.styles.layoutBadge.Render(fmt.Sprintf(" ▦ %d", count))
+		}
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
@@ -161,19 +190,19 @@ func (m ComponentListModel) View() string {
 	// Add scroll indicators if needed
 	if m.scrollOffset > 0 {
 		// Can scroll up
-		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  ▲ more"))
+		b.WriteString("\n" + m.styles.more.Render("  ▲ more"))
 	}
 	if endIdx < len(m.components) {
 		// Can scroll down
-		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("  ▼ more"))
+		b.WriteString("\n" + m.styles.more.Render("  ▼ more"))
 	}
 
 	content := b.String()
 
 	// Apply border
-	borderStyle := listBorderStyle
+	borderStyle := m.styles.listBorder
 	if m.focused {
-		borderStyle = listBorderFocusedStyle
+		borderStyle = m.styles.listBorderFocused
 	}
 
 	return borderStyle.
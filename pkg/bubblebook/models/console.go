@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/console"
+)
+
+// messageConsole is a prompt for typing messages to send to the active
+// component, such as "key ctrl+c" or "resize 50x12"
+type messageConsole struct {
+	open   bool
+	input  []rune
+	cursor int
+	err    string
+
+	// history holds the lines sent so far, oldest first, and recall is the
+	// one shown while browsing it, or len(history) for a new line
+	history []string
+	recall  int
+
+	// commands are the active story's own commands
+	commands map[string]console.ParseFunc
+}
+
+// show opens the console on an empty line, completing the commands of the
+// active story along with the others
+func (c *messageConsole) show(commands map[string]console.ParseFunc) {
+	c.open = true
+	c.commands = commands
+	c.setInput("")
+	c.recall = len(c.history)
+	c.err = ""
+}
+
+// setInput replaces the line, with the cursor at its end
+func (c *messageConsole) setInput(line string) {
+	c.input = []rune(line)
+	c.cursor = len(c.input)
+}
+
+// remember adds a line that was sent to the history and clears the prompt
+// for the next one
+func (c *messageConsole) remember(line string) {
+	if len(c.history) == 0 || c.history[len(c.history)-1] != line {
+		c.history = append(c.history, line)
+	}
+	c.recall = len(c.history)
+	c.setInput("")
+	c.err = ""
+}
+
+// update edits the line with a key. It returns the line when enter is
+// pressed.
+func (c *messageConsole) update(msg tea.KeyMsg) (line string, submit bool) {
+	switch msg.String() {
+	case "esc":
+		c.open = false
+	case "enter":
+		return string(c.input), true
+	case "left", "ctrl+b":
+		c.cursor = max(c.cursor-1, 0)
+	case "right", "ctrl+f":
+		c.cursor = min(c.cursor+1, len(c.input))
+	case "home", "ctrl+a":
+		c.cursor = 0
+	case "end", "ctrl+e":
+		c.cursor = len(c.input)
+	case "backspace":
+		if c.cursor > 0 {
+			c.input = slices.Delete(c.input, c.cursor-1, c.cursor)
+			c.cursor--
+		}
+	case "delete", "ctrl+d":
+		if c.cursor < len(c.input) {
+			c.input = slices.Delete(c.input, c.cursor, c.cursor+1)
+		}
+	case "ctrl+u":
+		c.input = c.input[c.cursor:]
+		c.cursor = 0
+	case "up":
+		if c.recall > 0 {
+			c.recall--
+			c.setInput(c.history[c.recall])
+		}
+	case "down":
+		if c.recall < len(c.history) {
+			c.recall++
+			line := ""
+			if c.recall < len(c.history) {
+				line = c.history[c.recall]
+			}
+			c.setInput(line)
+		}
+	case "tab":
+		c.complete()
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			c.input = slices.Insert(c.input, c.cursor, msg.Runes...)
+			c.cursor += len(msg.Runes)
+		}
+	}
+	c.err = ""
+	return "", false
+}
+
+// complete finishes the command name at the start of the line, when only
+// one command starts with what was typed
+func (c *messageConsole) complete() {
+	typed := string(c.input)
+	if strings.Contains(typed, " ") {
+		return
+	}
+	var match string
+	for _, name := range console.Commands(c.commands) {
+		if strings.HasPrefix(name, typed) {
+			if match != "" {
+				return
+			}
+			match = name
+		}
+	}
+	if match != "" {
+		c.setInput(match + " ")
+	}
+}
+
+// View renders the prompt on one line of the given width, followed by the
+// last error or by the usage of the command being typed
+func (c *messageConsole) View(st *styles, width int) string {
+	var b strings.Builder
+	b.WriteString(st.consolePrompt.Render("› "))
+	b.WriteString(string(c.input[:c.cursor]))
+	if c.cursor < len(c.input) {
+		b.WriteString(st.consoleCursor.Render(string(c.input[c.cursor])))
+		b.WriteString(string(c.input[c.cursor+1:]))
+	} else {
+		b.WriteString(st.consoleCursor.Render(" "))
+	}
+
+	name, _, _ := strings.Cut(strings.TrimSpace(string(c.input)), " ")
+	switch {
+	case c.err != "":
+		b.WriteString("  " + st.consoleError.Render(c.err))
+	case len(c.input) == 0:
+		b.WriteString("  " + st.help.Render(fmt.Sprintf("%s • tab completes • esc closes", strings.Join(console.Commands(c.commands), ", "))))
+	case console.Usage(name) != "":
+		b.WriteString("  " + st.help.Render(console.Usage(name)))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(b.String())
+}
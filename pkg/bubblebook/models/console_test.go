@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/console"
+)
+
+// listener is a story that shows the last note it was sent.
+type listener struct {
+	last noteMsg
+}
+
+func (l listener) Init() tea.Cmd { return nil }
+
+func (l listener) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(noteMsg); ok {
+		l.last = msg
+	}
+	return l, nil
+}
+
+func (l listener) View() string { return string(l.last) }
+
+// noteCommand is a console command that sends a note with a prefix.
+func noteCommand(prefix string) map[string]console.ParseFunc {
+	return map[string]console.ParseFunc{
+		"fail": func(args string) (tea.Msg, error) {
+			return noteMsg(prefix + args), nil
+		},
+	}
+}
+
+func TestConsoleSendsStoryCommands(t *testing.T) {
+	m := NewBubblebookModel([]ComponentEntry{
+		{Name: "First", Factory: func() tea.Model { return listener{} }, ConsoleMsgs: noteCommand("first ")},
+		{Name: "Second", Factory: func() tea.Model { return listener{} }, ConsoleMsgs: noteCommand("second ")},
+	})
+	m = send(m, tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.Init()
+
+	// Both stories have a command of the same name, each sending its own
+	for _, want := range []string{"first timeout", "second timeout"} {
+		m = send(m,
+			tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")},
+			tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fail timeout")},
+			tea.KeyMsg{Type: tea.KeyEnter},
+			tea.KeyMsg{Type: tea.KeyEsc},
+		)
+		if m.console.err != "" {
+			t.Fatalf("the console refused the story's command: %s", m.console.err)
+		}
+		if got := m.preview.ComponentView(); got != want {
+			t.Errorf("the story was sent %q, want %q", got, want)
+		}
+		m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	}
+}
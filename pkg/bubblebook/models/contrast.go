@@ -0,0 +1,84 @@
+package models
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/contrast"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+)
+
+var (
+	// contrastFlagFg and contrastFlagBg are the colours of text that fails
+	// the audit
+	contrastFlagFg = ansi.IndexedColor(231)
+	contrastFlagBg = ansi.IndexedColor(124)
+)
+
+// contrastAudit flags text with too little contrast in the view the preview
+// shows
+type contrastAudit struct {
+	enabled bool
+	options contrast.Options
+
+	lastView string
+	failing  []contrast.Region
+	worst    float64
+}
+
+// newContrastAudit creates an audit that is switched off. A nil background
+// uses the default.
+func newContrastAudit(background color.Color) *contrastAudit {
+	if background == nil {
+		return &contrastAudit{options: contrast.DefaultOptions()}
+	}
+	return &contrastAudit{options: contrast.OnBackground(background)}
+}
+
+// toggle switches the audit on or off
+func (a *contrastAudit) toggle() {
+	a.enabled = !a.enabled
+	a.lastView = ""
+	a.failing = nil
+}
+
+// check audits the view the preview shows. It is called whenever that view
+// changes, so that rendering only draws the result.
+func (a *contrastAudit) check(view string) {
+	if !a.enabled || view == a.lastView {
+		return
+	}
+	a.lastView = view
+	a.failing = contrast.Audit(view, 0, 0, a.options)
+	a.worst = 0
+	for _, r := range a.failing {
+		if a.worst == 0 || r.Ratio < a.worst {
+			a.worst = r.Ratio
+		}
+	}
+}
+
+// render returns the view last checked with the failing text flagged
+func (a *contrastAudit) render(view string) string {
+	if len(a.failing) == 0 {
+		return view
+	}
+
+	buf := screen.Parse(view, 0, 0)
+	for _, r := range a.failing {
+		for x := r.X; x < r.X+r.Width; x++ {
+			c := buf.Cell(x, r.Y)
+			if c == nil || c.Width == 0 {
+				continue
+			}
+			c = c.Clone()
+			c.Style.Fg = contrastFlagFg
+			c.Style.Bg = contrastFlagBg
+			c.Style.Attrs &^= cellbuf.ReverseAttr | cellbuf.FaintAttr
+			buf.SetCell(x, r.Y, c)
+		}
+	}
+	return strings.ReplaceAll(cellbuf.Render(buf), "\r\n", "\n")
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shade is a story whose text turns too dark to read once it is sent a key.
+type shade struct {
+	dark bool
+}
+
+func (s shade) Init() tea.Cmd { return nil }
+
+func (s shade) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		s.dark = true
+	}
+	return s, nil
+}
+
+func (s shade) View() string {
+	if s.dark {
+		return "\x1b[38;2;40;40;40mhidden\x1b[0m"
+	}
+	return "shown"
+}
+
+func TestContrastAuditedOnUpdate(t *testing.T) {
+	m := NewPreviewModel()
+	m.SetSize(84, 28)
+	m.LoadComponent(shade{}, "Shade")
+	m.ToggleContrast()
+	if len(m.audit.failing) != 0 {
+		t.Fatalf("readable text was flagged: %+v", m.audit.failing)
+	}
+
+	// The audit runs as the story changes, before the preview is drawn
+	m.ForwardMessage(tea.KeyMsg{Type: tea.KeyUp})
+	if len(m.audit.failing) != 1 || m.audit.failing[0].Text != "hidden" {
+		t.Fatalf("after the update the audit flagged %+v, want the dark text", m.audit.failing)
+	}
+
+	audited := m.audit.lastView
+	view := m.View()
+	if m.audit.lastView != audited {
+		t.Error("drawing the preview audited the view again")
+	}
+	if !strings.Contains(view, "1 low contrast") {
+		t.Errorf("the title does not count the flagged text:\n%s", view)
+	}
+}
+
+func TestContrastAuditedWhileTimeTravelling(t *testing.T) {
+	m := NewPreviewModel()
+	m.SetSize(84, 28)
+	m.LoadComponent(shade{}, "Shade")
+	m.ForwardMessage(tea.KeyMsg{Type: tea.KeyUp})
+	m.ToggleContrast()
+
+	m.ToggleTimeTravel()
+	m.ForwardMessage(tea.KeyMsg{Type: tea.KeyLeft})
+	if len(m.audit.failing) != 0 {
+		t.Errorf("the earlier, readable state was flagged: %+v", m.audit.failing)
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+)
+
+const (
+	// diffHighlightDuration is how long a changed cell stays highlighted
+	diffHighlightDuration = 500 * time.Millisecond
+	// diffTickInterval is how often the preview redraws so highlights fade
+	diffTickInterval = 100 * time.Millisecond
+)
+
+// diffHighlight is the background given to changed cells
+var diffHighlight = ansi.IndexedColor(22)
+
+// diffTickMsg redraws the preview while diff mode is on. It carries the
+// run of diff mode it belongs to, so ticks of a run that was switched off
+// are dropped instead of starting a second redraw loop.
+type diffTickMsg struct {
+	run int
+}
+
+// diffRunCounter numbers the times diff mode is switched on across every
+// preview, so a tick can only ever match the run that scheduled it
+var diffRunCounter atomic.Int64
+
+// diffTick schedules the next redraw of a run
+func diffTick(run int) tea.Cmd {
+	return tea.Tick(diffTickInterval, func(time.Time) tea.Msg {
+		return diffTickMsg{run: run}
+	})
+}
+
+// cellPos is the position of a cell in a view
+type cellPos struct {
+	x, y int
+}
+
+// differ compares each rendered view with the previous one and highlights
+// the cells that changed
+type differ struct {
+	enabled bool
+	// run identifies the current run of diff mode
+	run int
+
+	lastView string
+	last     *cellbuf.Buffer
+	// expires holds when each highlighted cell stops being highlighted
+	expires map[cellPos]time.Time
+
+	frames  int
+	changed int
+}
+
+// newDiffer creates a differ that is switched off
+func newDiffer() *differ {
+	return &differ{expires: make(map[cellPos]time.Time)}
+}
+
+// toggle switches diff mode on or off, starting a new run
+func (d *differ) toggle() {
+	d.enabled = !d.enabled
+	d.run = int(diffRunCounter.Add(1))
+	d.reset()
+}
+
+// reset forgets the previous frame
+func (d *differ) reset() {
+	d.lastView = ""
+	d.last = nil
+	clear(d.expires)
+	d.frames = 0
+	d.changed = 0
+}
+
+// render compares a view with the previous frame and returns it with the
+// recently changed cells highlighted
+func (d *differ) render(view string, now time.Time) string {
+	buf := screen.Parse(view, 0, 0)
+
+	if view != d.lastView {
+		if d.last != nil {
+			d.changed = 0
+			for y := 0; y < buf.Height(); y++ {
+				for x := 0; x < buf.Width(); x++ {
+					if !cellsEqual(buf.Cell(x, y), d.last.Cell(x, y)) {
+						d.changed++
+						d.expires[cellPos{x, y}] = now.Add(diffHighlightDuration)
+					}
+				}
+			}
+		}
+		d.frames++
+		d.lastView = view
+		d.last = buf
+	}
+
+	highlighted := false
+	for pos, until := range d.expires {
+		if now.After(until) {
+			delete(d.expires, pos)
+			continue
+		}
+
+		c := buf.Cell(pos.x, pos.y)
+		if c == nil || c.Width == 0 {
+			// Placeholders of wide characters are drawn by their first cell
+			continue
+		}
+		c = c.Clone()
+		c.Style.Bg = diffHighlight
+		buf.SetCell(pos.x, pos.y, c)
+		highlighted = true
+	}
+
+	if !highlighted {
+		return view
+	}
+	return strings.ReplaceAll(cellbuf.Render(buf), "\r\n", "\n")
+}
+
+// cellsEqual compares the content and style of two cells
+func cellsEqual(a, b *cellbuf.Cell) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffTicksOfEarlierRunsLapse(t *testing.T) {
+	m := newTestPreview(84, 28)
+
+	if m.ToggleDiff() == nil {
+		t.Fatal("switching diff mode on did not schedule a redraw")
+	}
+	first := m.diff.run
+	if m.advanceDiff(diffTickMsg{run: first}) == nil {
+		t.Error("a tick of the current run did not schedule the next one")
+	}
+
+	m.ToggleDiff()
+	if m.advanceDiff(diffTickMsg{run: first}) != nil {
+		t.Error("a tick scheduled the next one after diff mode was switched off")
+	}
+
+	// Switching back on before the old tick arrives must not start a
+	// second redraw loop
+	m.ToggleDiff()
+	if m.diff.run == first {
+		t.Fatal("switching diff mode on again did not start a new run")
+	}
+	if m.advanceDiff(diffTickMsg{run: first}) != nil {
+		t.Error("a tick of the earlier run was re-armed")
+	}
+	if m.advanceDiff(diffTickMsg{run: m.diff.run}) == nil {
+		t.Error("a tick of the new run did not schedule the next one")
+	}
+}
+
+func TestDiffHighlightsChangedCells(t *testing.T) {
+	d := newDiffer()
+	d.toggle()
+	now := time.Now()
+
+	d.render("count 1", now)
+	got := d.render("count 2", now)
+	if d.changed != 1 {
+		t.Errorf("%d cells changed, want 1", d.changed)
+	}
+	if !strings.Contains(got, "48;5;22") {
+		t.Errorf("the changed cell is not highlighted: %q", got)
+	}
+
+	if got := d.render("count 2", now.Add(diffHighlightDuration+time.Millisecond)); got != "count 2" {
+		t.Errorf("the highlight did not fade: %q", got)
+	}
+}
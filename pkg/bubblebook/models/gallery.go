@@ -0,0 +1,260 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// galleryTile is one running story in the gallery
+type galleryTile struct {
+	// index is the position of the story in the sidebar
+	index     int
+	name      string
+	component tea.Model
+	loadID    int
+	size      tea.WindowSizeMsg
+}
+
+// GalleryModel shows every story of a group at once, each running in its
+// own tile
+type GalleryModel struct {
+	group   string
+	tiles   []*galleryTile
+	cursor  int
+	columns int
+	width   int
+	height  int
+	focused bool
+	visible bool
+	styles  *styles
+}
+
+// NewGalleryModel creates a new gallery model
+func NewGalleryModel() *GalleryModel {
+	return &GalleryModel{styles: defaultStyles}
+}
+
+// Visible returns whether the gallery replaces the preview
+func (m *GalleryModel) Visible() bool {
+	return m.visible
+}
+
+// Group returns the group the gallery shows
+func (m *GalleryModel) Group() string {
+	return m.group
+}
+
+// SetFocused sets the focus state
+func (m *GalleryModel) SetFocused(focused bool) {
+	m.focused = focused
+}
+
+// Open creates an instance of every story in a group and returns their
+// Init commands. The tile of the selected story gets the cursor.
+func (m *GalleryModel) Open(group string, components []ComponentEntry, selected int) tea.Cmd {
+	m.group = group
+	m.tiles = nil
+	m.cursor = 0
+	m.visible = true
+
+	var cmds []tea.Cmd
+	for i, entry := range components {
+		if StoryGroup(entry.Name) != group {
+			continue
+		}
+		if i == selected {
+			m.cursor = len(m.tiles)
+		}
+
+		tile := &galleryTile{
+			index:     i,
+			name:      entry.Name,
+			component: entry.Factory(),
+			loadID:    nextLoadID(),
+		}
+		m.tiles = append(m.tiles, tile)
+		cmds = append(cmds, tagCmd(tile.loadID, tile.component.Init()))
+	}
+
+	return tea.Batch(append(cmds, m.layout())...)
+}
+
+// Close stops every tile. Results of their pending commands are dropped.
+func (m *GalleryModel) Close() {
+	m.visible = false
+	m.tiles = nil
+}
+
+// SetSize updates the dimensions and resizes every tile, returning the
+// commands they respond with
+func (m *GalleryModel) SetSize(width, height int) tea.Cmd {
+	m.width = width
+	m.height = height
+	return m.layout()
+}
+
+// layout arranges the tiles in a grid and sends each one its size
+func (m *GalleryModel) layout() tea.Cmd {
+	if !m.visible || len(m.tiles) == 0 || m.width == 0 || m.height == 0 {
+		return nil
+	}
+
+	m.columns = int(math.Ceil(math.Sqrt(float64(len(m.tiles)))))
+	rows := (len(m.tiles) + m.columns - 1) / m.columns
+	tileWidth, tileHeight := m.gridWidth()/m.columns, m.gridHeight()/rows
+
+	// Each tile has a border and a caption line
+	size := tea.WindowSizeMsg{Width: max(tileWidth-2, 0), Height: max(tileHeight-3, 0)}
+
+	var cmds []tea.Cmd
+	for _, tile := range m.tiles {
+		if tile.size == size {
+			continue
+		}
+		tile.size = size
+		cmds = append(cmds, m.updateTile(tile, size))
+	}
+	return tea.Batch(cmds...)
+}
+
+// gridWidth and gridHeight return the space available to the tiles
+func (m *GalleryModel) gridWidth() int {
+	return m.width - 2
+}
+
+func (m *GalleryModel) gridHeight() int {
+	return m.height - 2
+}
+
+// updateTile sends a message to a tile and tags the command it returns
+func (m *GalleryModel) updateTile(tile *galleryTile, msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	tile.component, cmd = tile.component.Update(msg)
+	return tagCmd(tile.loadID, cmd)
+}
+
+// handleCommandResult delivers the result of a command to the tile that
+// issued it
+func (m *GalleryModel) handleCommandResult(msg componentMsg) tea.Cmd {
+	for _, tile := range m.tiles {
+		if tile.loadID == msg.id {
+			return m.updateTile(tile, msg.msg)
+		}
+	}
+	return nil
+}
+
+// Update moves the cursor between tiles. It returns the sidebar index of
+// the story to open in the preview when enter is pressed.
+func (m *GalleryModel) Update(msg tea.KeyMsg) (index int, open bool) {
+	if len(m.tiles) == 0 {
+		return 0, false
+	}
+
+	switch msg.String() {
+	case "left", "h":
+		m.moveCursor(-1)
+	case "right", "l":
+		m.moveCursor(1)
+	case "up", "k":
+		m.moveCursor(-m.columns)
+	case "down", "j":
+		m.moveCursor(m.columns)
+	case "enter":
+		return m.tiles[m.cursor].index, true
+	}
+	return 0, false
+}
+
+// moveCursor shifts the cursor, staying on the grid
+func (m *GalleryModel) moveCursor(delta int) {
+	if cursor := m.cursor + delta; cursor >= 0 && cursor < len(m.tiles) {
+		m.cursor = cursor
+	}
+}
+
+// View renders the gallery
+func (m GalleryModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	name := m.group
+	if name == "" {
+		name = "Ungrouped stories"
+	}
+	title := m.styles.previewTitle.Render(name) + " " +
+		m.styles.help.Render(fmt.Sprintf("%d variants", len(m.tiles)))
+
+	// Lay the tiles out row by row
+	var rows []string
+	for start := 0; start < len(m.tiles); start += m.columns {
+		var row []string
+		for i := start; i < min(start+m.columns, len(m.tiles)); i++ {
+			row = append(row, m.tileView(i))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	grid := lipgloss.NewStyle().
+		Height(m.gridHeight()).
+		MaxHeight(m.gridHeight()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+
+	help := m.styles.help.MaxWidth(m.gridWidth()).Render("←/→/↑/↓ move • enter open in preview • v close gallery")
+
+	borderStyle := m.styles.galleryBorder
+	if m.focused {
+		borderStyle = m.styles.galleryBorderFocused
+	}
+	return borderStyle.
+		Width(m.width).
+		Height(m.height).
+		Render(title + "\n" + grid + "\n" + help)
+}
+
+// tileView renders one tile with its caption
+func (m GalleryModel) tileView(i int) string {
+	tile := m.tiles[i]
+
+	caption := m.styles.tileCaption.Render(tile.name)
+	style := m.styles.tileBorder
+	if i == m.cursor {
+		caption = m.styles.cursor.Render("▶ ") + m.styles.previewTitle.Render(tile.name)
+		if m.focused {
+			style = m.styles.tileBorderSelected
+		}
+	}
+	caption = ansi.Truncate(caption, tile.size.Width, "…")
+
+	return style.
+		Width(tile.size.Width).
+		Height(tile.size.Height + 1).
+		Render(caption + "\n" + clipView(tile.component.View(), tile.size.Width, tile.size.Height))
+}
+
+// clipView cuts a view down to the given size, so that a component that
+// ignores its size cannot break the grid
+func clipView(view string, width, height int) string {
+	lines := strings.Split(view, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// StoryGroup returns the group of a story, which is the part of its name
+// before the last slash, as in "Button/Primary"
+func StoryGroup(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
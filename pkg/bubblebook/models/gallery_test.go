@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tileStory is a story whose Init command returns its label, and which
+// shows the labels it has been sent.
+type tileStory struct {
+	label string
+	got   []string
+}
+
+func (s tileStory) Init() tea.Cmd { return note(s.label) }
+
+func (s tileStory) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(noteMsg); ok {
+		s.got = append(s.got, string(msg))
+	}
+	return s, nil
+}
+
+func (s tileStory) View() string { return strings.Join(s.got, ",") }
+
+// tileStories returns entries for stories with the given names.
+func tileStories(names ...string) []ComponentEntry {
+	entries := make([]ComponentEntry, len(names))
+	for i, name := range names {
+		entries[i] = ComponentEntry{
+			Name:    name,
+			Factory: func() tea.Model { return tileStory{label: name} },
+		}
+	}
+	return entries
+}
+
+func TestGalleryOpensGroup(t *testing.T) {
+	m := NewGalleryModel()
+	m.Open("Button", tileStories("Button/Primary", "Input/Empty", "Button/Danger"), 2)
+
+	if len(m.tiles) != 2 {
+		t.Fatalf("the gallery has %d tiles, want the 2 stories of the group", len(m.tiles))
+	}
+	index, open := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !open || index != 2 {
+		t.Errorf("enter opens story %d (%v), want the selected story 2", index, open)
+	}
+}
+
+func TestGalleryRoutesCommandResults(t *testing.T) {
+	m := NewGalleryModel()
+	cmd := m.Open("Button", tileStories("Button/Primary", "Button/Danger"), 0)
+
+	for _, msg := range run(cmd) {
+		result, ok := msg.(componentMsg)
+		if !ok {
+			t.Fatalf("a tile's command resulted in an untagged %#v", msg)
+		}
+		m.handleCommandResult(result)
+	}
+
+	// Each tile only hears back from its own Init command
+	for _, tile := range m.tiles {
+		if got, want := tile.component.View(), tile.name; got != want {
+			t.Errorf("tile %s was sent %q, want only %q", tile.name, got, want)
+		}
+	}
+}
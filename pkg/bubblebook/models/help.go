@@ -6,86 +6,183 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	helpTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true).
-			Padding(0, 0, 1, 0)
-
-	helpSectionStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("141")).
-				Bold(true).
-				Padding(1, 0, 0, 0)
-
-	helpKeyStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("212")).
-			Bold(true)
-
-	helpDescStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("246"))
-
-	helpBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("205")).
-			Padding(2, 4).
-			Margin(1, 2)
-)
+// RenderHelp renders the help screen. isolateKey is the chord that leaves
+// isolated mode.
+func RenderHelp(width, height int, isolateKey string) string {
+	return renderHelp(defaultStyles, width, height, isolateKey)
+}
 
-// RenderHelp renders the help screen
-func RenderHelp(width, height int) string {
+// renderHelp renders the help screen with the given styles
+func renderHelp(st *styles, width, height int, isolateKey string) string {
 	var b strings.Builder
+	var sections []string
 
 	// Title
-	b.WriteString(helpTitleStyle.Render("Bubblebook - Keyboard Shortcuts"))
+	b.WriteString(st.helpTitle.Render("Bubblebook - Keyboard Shortcuts"))
 	b.WriteString("\n")
 
 	// Navigation section
-	b.WriteString(helpSectionStyle.Render("Navigation"))
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("Navigation"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  ↑/k, ↓/j  "))
+	b.WriteString(st.helpDesc.Render("Navigate component list"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  g, G      "))
+	b.WriteString(st.helpDesc.Render("Jump to top/bottom"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  enter     "))
+	b.WriteString(st.helpDesc.Render("Select component"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  v         "))
+	b.WriteString(st.helpDesc.Render("Show every story of the selected group in a gallery"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  i         "))
+	b.WriteString(st.helpDesc.Render("Run the active story alone, full-screen (" + isolateKey + " returns)"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  a         "))
+	b.WriteString(st.helpDesc.Render("Flag text with too little contrast in the active story"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  ↑/k, ↓/j  "))
-	b.WriteString(helpDescStyle.Render("Navigate component list"))
+	b.WriteString(st.helpKey.Render("  p         "))
+	b.WriteString(st.helpDesc.Render("Show the active story's presets; 1-9 then send them, even to a focused preview"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  g, G      "))
-	b.WriteString(helpDescStyle.Render("Jump to top/bottom"))
+	b.WriteString(st.helpKey.Render("  :         "))
+	b.WriteString(st.helpDesc.Render("Type messages to send to the active story, such as key enter or resize 50x12"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  enter     "))
-	b.WriteString(helpDescStyle.Render("Select component"))
+	b.WriteString(st.helpKey.Render("  c         "))
+	b.WriteString(st.helpDesc.Render("Hold the active story's commands; r runs, d delays, x drops, n selects, 1-9 resolve"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  w         "))
+	b.WriteString(st.helpDesc.Render("Draw ambiguous-width characters two cells wide, as CJK terminals do"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  t         "))
+	b.WriteString(st.helpDesc.Render("Paste the next wide, combining or joined stress text into the active story"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  s         "))
+	b.WriteString(st.helpDesc.Render("Grow the active story from 20x5 to full size to watch it reflow"))
 	b.WriteString("\n")
 
 	// Focus section
-	b.WriteString(helpSectionStyle.Render("Focus"))
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("Focus"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  tab       "))
+	b.WriteString(st.helpDesc.Render("Switch between list, previews, gallery and inspector"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  esc       "))
+	b.WriteString(st.helpDesc.Render("Return to component list"))
+	b.WriteString("\n")
+
+	// Tools section
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("Tools"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  tab       "))
-	b.WriteString(helpDescStyle.Render("Switch between list and preview"))
+	b.WriteString(st.helpKey.Render("  f2        "))
+	b.WriteString(st.helpDesc.Render("Start/stop recording the preview to an asciicast file"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  esc       "))
-	b.WriteString(helpDescStyle.Render("Return to component list"))
+	b.WriteString(st.helpKey.Render("  f3        "))
+	b.WriteString(st.helpDesc.Render("Restart the component and trace its messages / save the trace"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f4        "))
+	b.WriteString(st.helpDesc.Render("Replay the latest saved trace of the selected component"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f5        "))
+	b.WriteString(st.helpDesc.Render("Time travel: ←/→ browse earlier states, enter resumes from one"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f6        "))
+	b.WriteString(st.helpDesc.Render("Show/hide the model inspector (tab focuses it, ←/→ collapse/expand)"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f7        "))
+	b.WriteString(st.helpDesc.Render("Show the profiler / hide it and save its timings as CSV"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f8        "))
+	b.WriteString(st.helpDesc.Render("Highlight the cells each render changes"))
+	b.WriteString("\n")
+
+	// Compare section
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("Compare"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  f9        "))
+	b.WriteString(st.helpDesc.Render("Open the selected story side by side; pick another to compare"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  b         "))
+	b.WriteString(st.helpDesc.Render("Send every key to both stories (from the list)"))
+	b.WriteString("\n")
+	b.WriteString(st.helpKey.Render("  [, ]      "))
+	b.WriteString(st.helpDesc.Render("Move the divider between the stories (from the list)"))
 	b.WriteString("\n")
 
 	// General section
-	b.WriteString(helpSectionStyle.Render("General"))
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("General"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  ?         "))
-	b.WriteString(helpDescStyle.Render("Toggle this help screen"))
+	b.WriteString(st.helpKey.Render("  ?         "))
+	b.WriteString(st.helpDesc.Render("Toggle this help screen"))
 	b.WriteString("\n")
-	b.WriteString(helpKeyStyle.Render("  q, ctrl+c "))
-	b.WriteString(helpDescStyle.Render("Quit"))
+	b.WriteString(st.helpKey.Render("  q, ctrl+c "))
+	b.WriteString(st.helpDesc.Render("Quit"))
 	b.WriteString("\n")
 
 	// Component interaction
-	b.WriteString(helpSectionStyle.Render("Component Interaction"))
+	sections = append(sections, b.String())
+	b.Reset()
+	b.WriteString(st.helpSection.Render("Component Interaction"))
 	b.WriteString("\n")
-	b.WriteString(helpDescStyle.Render("  When preview is focused, all keys are forwarded to the active component."))
+	b.WriteString(st.helpDesc.Render("  When preview is focused, all keys are forwarded to the active component."))
 	b.WriteString("\n")
-	b.WriteString(helpDescStyle.Render("  Each component has its own keyboard shortcuts - check the component"))
+	b.WriteString(st.helpDesc.Render("  Each component has its own keyboard shortcuts - check the component"))
 	b.WriteString("\n")
-	b.WriteString(helpDescStyle.Render("  documentation for details."))
+	b.WriteString(st.helpDesc.Render("  documentation for details."))
 	b.WriteString("\n")
 
-	content := b.String()
+	sections = append(sections, b.String())
+	content := strings.Join(sections, "")
 
-	return helpBoxStyle.
+	// Put the sections side by side when they don't fit under each other,
+	// and cut them off when that is still too tall. The box takes 8 rows
+	// and 16 columns.
+	if lipgloss.Height(content) > height-8 {
+		columns := sections[0] + helpColumns(sections[1:])
+		if lipgloss.Width(columns) <= width-16 {
+			content = columns
+		}
+	}
+	if rows := height - 8; rows > 0 && lipgloss.Height(content) > rows {
+		lines := strings.Split(content, "\n")[:rows-1]
+		lines = append(lines, st.helpDesc.Render("… enlarge the terminal to see every shortcut"))
+		content = strings.Join(lines, "\n")
+	}
+
+	return st.helpBox.
 		Width(width - 8).
 		Height(height - 4).
 		Render(content)
 }
+
+// helpColumns lays the help sections out in two columns of about the same
+// height
+func helpColumns(sections []string) string {
+	total := lipgloss.Height(strings.Join(sections, ""))
+
+	var left, right strings.Builder
+	for _, section := range sections {
+		if lipgloss.Height(left.String()) < total/2 {
+			left.WriteString(section)
+		} else {
+			right.WriteString(section)
+		}
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		strings.TrimRight(left.String(), "\n"),
+		"    ",
+		strings.TrimRight(right.String(), "\n"),
+	)
+}
@@ -0,0 +1,441 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// inspectMaxDepth limits how deeply nested values are expanded
+	inspectMaxDepth = 12
+	// inspectMaxItems limits how many elements of a collection are shown
+	inspectMaxItems = 100
+)
+
+// inspectNode is a value in the inspector tree
+type inspectNode struct {
+	path     string
+	name     string
+	summary  string
+	children []*inspectNode
+	// changed is set when the value or one of its descendants changed in
+	// the last message
+	changed bool
+}
+
+// inspectRow is a node as it appears on screen
+type inspectRow struct {
+	node  *inspectNode
+	depth int
+}
+
+// InspectorModel shows the fields of the active component's model as an
+// expandable tree
+type InspectorModel struct {
+	width   int
+	height  int
+	focused bool
+	visible bool
+
+	root     *inspectNode
+	values   map[string]string
+	load     int
+	version  int
+	expanded map[string]bool
+	cursor   int
+	offset   int
+
+	styles *styles
+}
+
+// NewInspectorModel creates a new inspector model
+func NewInspectorModel() *InspectorModel {
+	return &InspectorModel{
+		version:  -1,
+		expanded: map[string]bool{"": true},
+		styles:   defaultStyles,
+	}
+}
+
+// SetSize updates the dimensions
+func (m *InspectorModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetFocused sets the focus state
+func (m *InspectorModel) SetFocused(focused bool) {
+	m.focused = focused
+}
+
+// Visible returns whether the inspector is shown
+func (m *InspectorModel) Visible() bool {
+	return m.visible
+}
+
+// Toggle shows or hides the inspector
+func (m *InspectorModel) Toggle() {
+	m.visible = !m.visible
+}
+
+// Observe inspects a model. load identifies the component instance and
+// version its state, so the tree is only rebuilt, and changes are only
+// highlighted, when the state is new.
+func (m *InspectorModel) Observe(model tea.Model, load, version int) {
+	if !m.visible || (load == m.load && version == m.version) {
+		return
+	}
+	if load != m.load {
+		// A different component was loaded, so nothing can be compared
+		m.values = nil
+		m.expanded = map[string]bool{"": true}
+		m.cursor = 0
+		m.offset = 0
+	}
+	m.load = load
+	m.version = version
+
+	if model == nil {
+		m.root = nil
+		m.values = nil
+		return
+	}
+
+	values := make(map[string]string)
+	root := inspectValue("", typeLabel(model), reflect.ValueOf(model), 0, make(map[visitKey]bool), values)
+
+	if m.values != nil {
+		markChanged(root, m.values, values)
+	}
+	m.root = root
+	m.values = values
+	m.clampCursor()
+}
+
+// Update handles navigation keys
+func (m InspectorModel) Update(msg tea.Msg) (InspectorModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.root == nil {
+		return m, nil
+	}
+
+	rows := m.rows()
+	switch keyMsg.String() {
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.visibleLines()
+	case "pgdown":
+		m.cursor += m.visibleLines()
+	case "g", "home":
+		m.cursor = 0
+	case "G", "end":
+		m.cursor = len(rows) - 1
+	case "right", "l":
+		if node := rows[m.cursor].node; len(node.children) > 0 {
+			m.expanded[node.path] = true
+		}
+	case "left", "h":
+		node := rows[m.cursor].node
+		if m.expanded[node.path] && len(node.children) > 0 {
+			delete(m.expanded, node.path)
+		} else {
+			// Jump to the parent
+			for i := m.cursor - 1; i >= 0; i-- {
+				if rows[i].depth < rows[m.cursor].depth {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	case "enter", " ":
+		if node := rows[m.cursor].node; len(node.children) > 0 {
+			m.expanded[node.path] = !m.expanded[node.path]
+		}
+	}
+	m.clampCursor()
+
+	return m, nil
+}
+
+// rows flattens the expanded part of the tree
+func (m *InspectorModel) rows() []inspectRow {
+	var rows []inspectRow
+	var walk func(node *inspectNode, depth int)
+	walk = func(node *inspectNode, depth int) {
+		rows = append(rows, inspectRow{node: node, depth: depth})
+		if m.expanded[node.path] {
+			for _, child := range node.children {
+				walk(child, depth+1)
+			}
+		}
+	}
+	if m.root != nil {
+		walk(m.root, 0)
+	}
+	return rows
+}
+
+// visibleLines returns how many rows fit in the panel
+func (m *InspectorModel) visibleLines() int {
+	return max(m.height-4, 1) // Account for border and title
+}
+
+// clampCursor keeps the cursor on a row and scrolls it into view
+func (m *InspectorModel) clampCursor() {
+	count := len(m.rows())
+	m.cursor = min(max(m.cursor, 0), max(count-1, 0))
+
+	visible := m.visibleLines()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// View renders the inspector
+func (m InspectorModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.listTitle.Render("Inspector"))
+	b.WriteString("\n\n")
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		b.WriteString(m.styles.emptyState.Render("No component"))
+	}
+
+	end := min(m.offset+m.visibleLines(), len(rows))
+	for i := m.offset; i < end; i++ {
+		row := rows[i]
+
+		marker := "  "
+		if len(row.node.children) > 0 {
+			if m.expanded[row.node.path] {
+				marker = "▾ "
+			} else {
+				marker = "▸ "
+			}
+		}
+
+		line := strings.Repeat("  ", row.depth) + marker +
+			m.styles.inspectorName.Render(row.node.name) + " " +
+			m.styles.inspectorValue.Render(row.node.summary)
+		// Leave room for the padding and the cursor column
+		line = lipgloss.NewStyle().MaxWidth(m.width - 3).Render(line)
+		if row.node.changed {
+			line = m.styles.inspectorChanged.Render(ansi.Strip(line))
+		}
+		if i == m.cursor && m.focused {
+			line = m.styles.inspectorCursor.Render("▶") + line
+		} else {
+			line = " " + line
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	borderStyle := m.styles.inspectorBorder
+	if m.focused {
+		borderStyle = m.styles.inspectorBorderFocused
+	}
+
+	return borderStyle.
+		Width(m.width).
+		Height(m.height).
+		Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// visitKey identifies a pointer-like value for cycle detection
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// inspectValue builds the tree for a value, recording each node's summary
+// in values. visiting holds the pointers on the path from the root, so that
+// cycles end in a marker instead of recursing forever.
+func inspectValue(path, name string, v reflect.Value, depth int, visiting map[visitKey]bool, values map[string]string) *inspectNode {
+	node := &inspectNode{path: path, name: name}
+	defer func() {
+		values[path] = node.summary
+	}()
+
+	if !v.IsValid() {
+		node.summary = "nil"
+		return node
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			node.summary = "nil"
+			return node
+		}
+		key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if visiting[key] {
+			node.summary = "↺ cycle"
+			return node
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	if depth >= inspectMaxDepth {
+		node.summary = v.Type().String() + " …"
+		return node
+	}
+
+	child := func(childPath, childName string, cv reflect.Value) {
+		node.children = append(node.children, inspectValue(childPath, childName, cv, depth+1, visiting, values))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := inspectValue(path, name, v.Elem(), depth, visiting, values)
+		node.summary = "&" + elem.summary
+		node.children = elem.children
+
+	case reflect.Interface:
+		if v.IsNil() {
+			node.summary = "nil"
+			return node
+		}
+		elem := inspectValue(path, name, v.Elem(), depth, visiting, values)
+		node.summary = elem.summary
+		node.children = elem.children
+
+	case reflect.Struct:
+		node.summary = v.Type().String() + "{}"
+		if v.NumField() > 0 {
+			node.summary = v.Type().String() + "{…}"
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			child(path+"."+field.Name, field.Name, v.Field(i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		node.summary = fmt.Sprintf("%s len=%d", v.Type().String(), v.Len())
+		for i := 0; i < min(v.Len(), inspectMaxItems); i++ {
+			child(fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("[%d]", i), v.Index(i))
+		}
+
+	case reflect.Map:
+		node.summary = fmt.Sprintf("%s len=%d", v.Type().String(), v.Len())
+		keys := v.MapKeys()
+		labels := make([]string, len(keys))
+		for i, k := range keys {
+			labels[i] = keyLabel(k)
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return labels[order[a]] < labels[order[b]] })
+		seen := make(map[string]int, len(keys))
+		for n, i := range order {
+			if n >= inspectMaxItems {
+				break
+			}
+			// Keys that format alike, such as NaN, still need their own
+			// paths for changes to be tracked
+			label := labels[i]
+			if seen[label]++; seen[label] > 1 {
+				label += fmt.Sprintf(" #%d", seen[label])
+			}
+			child(path+"["+label+"]", "["+label+"]", v.MapIndex(keys[i]))
+		}
+
+	default:
+		node.summary = scalarSummary(v)
+	}
+
+	return node
+}
+
+// scalarSummary formats a value that has no children. It only uses the
+// reflect accessors that work on unexported fields.
+func scalarSummary(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, 128)
+	case reflect.String:
+		s := v.String()
+		if utf8.RuneCountInString(s) > 80 {
+			s = string([]rune(s)[:80]) + "…"
+		}
+		return strconv.Quote(s)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("%s %#x", v.Type().String(), v.Pointer())
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+	return v.Type().String()
+}
+
+// keyLabel formats a map key. Keys that scalarSummary would only name the
+// type of, such as structs, are printed in full so they can be told apart.
+func keyLabel(k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.Interface:
+		if !k.IsNil() {
+			return keyLabel(k.Elem())
+		}
+	case reflect.Struct, reflect.Array, reflect.Pointer:
+		return fmt.Sprintf("%v", k)
+	}
+	return scalarSummary(k)
+}
+
+// typeLabel returns the type name of a value
+func typeLabel(value any) string {
+	if value == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// markChanged flags nodes whose summary differs from the previous state,
+// along with their ancestors
+func markChanged(node *inspectNode, previous, current map[string]string) bool {
+	changed := previous[node.path] != current[node.path]
+	if _, existed := previous[node.path]; !existed {
+		changed = true
+	}
+	for _, child := range node.children {
+		if markChanged(child, previous, current) {
+			changed = true
+		}
+	}
+	node.changed = changed
+	return changed
+}
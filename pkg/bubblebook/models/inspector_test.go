@@ -0,0 +1,94 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// linked is a model that can refer back to itself.
+type linked struct {
+	name string
+	next *linked
+}
+
+// point is a comparable struct used as a map key.
+type point struct {
+	x, y int
+}
+
+// inspect builds the inspector tree of a value.
+func inspect(value any) *inspectNode {
+	return inspectValue("", typeLabel(value), reflect.ValueOf(value), 0, make(map[visitKey]bool), make(map[string]string))
+}
+
+// childNamed returns the child of node with the given name.
+func childNamed(t *testing.T, node *inspectNode, name string) *inspectNode {
+	t.Helper()
+	for _, child := range node.children {
+		if child.name == name {
+			return child
+		}
+	}
+	t.Fatalf("%s has no child %s", node.path, name)
+	return nil
+}
+
+func TestInspectCycle(t *testing.T) {
+	l := &linked{name: "loop"}
+	l.next = l
+
+	root := inspect(l)
+	if got := childNamed(t, root, "next").summary; got != "↺ cycle" {
+		t.Errorf("a model pointing at itself is summarised as %q, want a cycle", got)
+	}
+
+	// The same value reached twice without a cycle is expanded both times
+	shared := &linked{name: "shared"}
+	root = inspect(struct{ a, b *linked }{shared, shared})
+	for _, name := range []string{"a", "b"} {
+		if got := childNamed(t, root, name).summary; got == "↺ cycle" {
+			t.Errorf("field %s is reported as a cycle", name)
+		}
+	}
+}
+
+func TestInspectLongString(t *testing.T) {
+	root := inspect(struct{ text string }{strings.Repeat("é", 100)})
+
+	got := childNamed(t, root, "text").summary
+	if want := `"` + strings.Repeat("é", 80) + `…"`; got != want {
+		t.Errorf("a long string is summarised as %s, want it cut after 80 runes", got)
+	}
+}
+
+func TestInspectStructKeys(t *testing.T) {
+	root := inspect(map[point]string{{1, 2}: "a", {3, 4}: "b"})
+
+	if len(root.children) != 2 {
+		t.Fatalf("the map has %d children, want 2", len(root.children))
+	}
+	if a, b := root.children[0], root.children[1]; a.path == b.path {
+		t.Errorf("both keys have the path %q", a.path)
+	}
+	if got := childNamed(t, root, "[{1 2}]").summary; got != `"a"` {
+		t.Errorf("the key {1 2} holds %s, want \"a\"", got)
+	}
+}
+
+func TestInspectorMarksChanges(t *testing.T) {
+	m := NewInspectorModel()
+	m.Toggle()
+	m.Observe(probe{width: 10, height: 5}, 1, 0)
+	m.Observe(probe{width: 10, height: 6}, 1, 1)
+
+	if !childNamed(t, m.root, "height").changed {
+		t.Error("the changed field is not highlighted")
+	}
+	if childNamed(t, m.root, "width").changed {
+		t.Error("an unchanged field is highlighted")
+	}
+	if !m.root.changed {
+		t.Error("the parent of a changed field is not highlighted")
+	}
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// interceptDelay is how long a delayed command waits before it runs
+	interceptDelay = 2 * time.Second
+	// interceptShown is the number of held commands listed at once
+	interceptShown = 5
+)
+
+// heldCmd is a command a component returned while commands were
+// intercepted, waiting to be run, delayed, dropped or resolved
+type heldCmd struct {
+	cmd tea.Cmd
+	// name is the function the command runs, such as
+	// "spinner.Model.tick.func1"
+	name string
+	// cause is the message the component returned the command for
+	cause string
+}
+
+// interceptor holds the commands a component returns instead of running
+// them, so that slow, failing or out of order results can be staged by hand
+type interceptor struct {
+	enabled  bool
+	held     []heldCmd
+	selected int
+}
+
+// hold adds a command to the end of the queue
+func (i *interceptor) hold(cmd tea.Cmd, cause string) {
+	i.held = append(i.held, heldCmd{cmd: cmd, name: cmdName(cmd), cause: cause})
+}
+
+// take removes the selected command from the queue
+func (i *interceptor) take() (heldCmd, bool) {
+	if len(i.held) == 0 {
+		return heldCmd{}, false
+	}
+	held := i.held[i.selected]
+	i.held = slices.Delete(i.held, i.selected, i.selected+1)
+	if i.selected >= len(i.held) {
+		i.selected = max(len(i.held)-1, 0)
+	}
+	return held, true
+}
+
+// next selects the following command, wrapping around at the end
+func (i *interceptor) next() {
+	if len(i.held) > 0 {
+		i.selected = (i.selected + 1) % len(i.held)
+	}
+}
+
+// release empties the queue, returning every command that was held
+func (i *interceptor) release() []heldCmd {
+	held := i.held
+	i.held = nil
+	i.selected = 0
+	return held
+}
+
+// cmdName returns the name of the function a command runs, without its
+// package path
+func cmdName(cmd tea.Cmd) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(cmd).Pointer())
+	if fn == nil {
+		return "command"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// View renders the queue of held commands and the keys that act on the
+// selected one
+func (i *interceptor) View(st *styles) string {
+	var b strings.Builder
+	b.WriteString(st.previewTitle.Render(fmt.Sprintf("Commands held: %d", len(i.held))))
+	if len(i.held) == 0 {
+		b.WriteString("\n")
+		b.WriteString(st.help.Render("Commands the story returns wait here"))
+	}
+
+	// Keep the selected command in the window of listed ones
+	first := max(min(i.selected-interceptShown/2, len(i.held)-interceptShown), 0)
+	last := min(first+interceptShown, len(i.held))
+	for j := first; j < last; j++ {
+		held := i.held[j]
+		line := fmt.Sprintf("  %s  from %s", held.name, held.cause)
+		if j == i.selected {
+			line = st.interceptSelected.Render("▸ " + line[2:])
+		}
+		b.WriteString("\n")
+		b.WriteString(line)
+	}
+	if hidden := len(i.held) - (last - first); hidden > 0 {
+		b.WriteString("\n")
+		b.WriteString(st.help.Render(fmt.Sprintf("… %d more", hidden)))
+	}
+
+	if len(i.held) > 0 {
+		b.WriteString("\n")
+		b.WriteString(st.help.Render(fmt.Sprintf("r run • d delay %s • x drop • n next • 1-9 resolve with a preset", interceptDelay)))
+	}
+	return st.interceptBox.Render(b.String())
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultIsolateKey is the chord that leaves isolated mode unless
+// configured otherwise
+const defaultIsolateKey = "ctrl+]"
+
+// IsolatedModel runs a single story as the root model of a program, with
+// no bubblebook chrome around it. The story gets the terminal's own size
+// and every message, except for the exit chord, which quits.
+type IsolatedModel struct {
+	component tea.Model
+	exitKey   string
+}
+
+// NewIsolatedModel wraps a story. An empty exit key uses the default.
+func NewIsolatedModel(component tea.Model, exitKey string) IsolatedModel {
+	if exitKey == "" {
+		exitKey = defaultIsolateKey
+	}
+	return IsolatedModel{component: component, exitKey: exitKey}
+}
+
+// Init initializes the story
+func (m IsolatedModel) Init() tea.Cmd {
+	return m.component.Init()
+}
+
+// Update forwards every message to the story
+func (m IsolatedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == m.exitKey {
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.component, cmd = m.component.Update(msg)
+	return m, cmd
+}
+
+// View renders the story alone
+func (m IsolatedModel) View() string {
+	return m.component.View()
+}
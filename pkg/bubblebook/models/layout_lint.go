@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/lint"
+)
+
+// layoutLinter checks every rendered view against the size the component
+// was given
+type layoutLinter struct {
+	lastView string
+	lastSize tea.WindowSizeMsg
+	warnings []lint.Warning
+}
+
+// check lints a view, unless it was already linted at this size
+func (l *layoutLinter) check(view string, size tea.WindowSizeMsg) {
+	if view == l.lastView && size == l.lastSize {
+		return
+	}
+	l.lastView = view
+	l.lastSize = size
+	l.warnings = lint.Check(view, size.Width, size.Height)
+}
+
+// reset forgets the last view, for a newly loaded component
+func (l *layoutLinter) reset() {
+	l.lastView = ""
+	l.lastSize = tea.WindowSizeMsg{}
+	l.warnings = nil
+}
+
+// View lists the warnings on one line
+func (l *layoutLinter) View(st *styles) string {
+	if len(l.warnings) == 0 {
+		return ""
+	}
+	parts := make([]string, len(l.warnings))
+	for i, w := range l.warnings {
+		parts[i] = w.String()
+	}
+	return st.layoutBadge.Render("⚠ " + strings.Join(parts, " • "))
+}
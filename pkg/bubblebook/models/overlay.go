@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// overlay draws fg on top of bg with its top-left corner at column x and
+// row y. Both may contain ANSI styles; cells of bg outside fg are kept.
+func overlay(bg, fg string, x, y int) string {
+	bgLines := strings.Split(bg, "\n")
+
+	for i, line := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(bgLines) {
+			continue
+		}
+
+		under := bgLines[row]
+		left := ansi.Truncate(under, x, "")
+		if pad := x - ansi.StringWidth(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		right := ansi.TruncateLeft(under, x+ansi.StringWidth(line), "")
+
+		bgLines[row] = left + ansi.ResetStyle + line + ansi.ResetStyle + right
+	}
+
+	return strings.Join(bgLines, "\n")
+}
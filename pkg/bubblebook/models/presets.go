@@ -0,0 +1,48 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxPresetKeys is the number of presets bound to the keys 1 to 9
+const maxPresetKeys = 9
+
+// Preset is a named list of messages a story can be sent with one key,
+// such as "Simulate network error"
+type Preset struct {
+	Label string
+	Msgs  []tea.Msg
+}
+
+// presetIndex returns the preset a key triggers, if it is one of 1 to 9
+func presetIndex(key string, presets []Preset) (int, bool) {
+	if len(key) != 1 || key[0] < '1' || key[0] > '9' {
+		return 0, false
+	}
+	index := int(key[0] - '1')
+	return index, index < len(presets)
+}
+
+// renderPresets draws the panel listing a story's presets and their keys
+func renderPresets(st *styles, presets []Preset) string {
+	var b strings.Builder
+	b.WriteString(st.previewTitle.Render("Presets"))
+	if len(presets) == 0 {
+		b.WriteString("\n")
+		b.WriteString(st.help.Render("None registered for this story"))
+	}
+	for i, preset := range presets {
+		key := "·"
+		if i < maxPresetKeys {
+			key = fmt.Sprint(i + 1)
+		}
+		b.WriteString("\n")
+		b.WriteString(st.helpKey.Render(key))
+		b.WriteString(" ")
+		b.WriteString(preset.Label)
+	}
+	return st.presetBox.Render(b.String())
+}
@@ -1,34 +1,34 @@
 package models
 
 import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/contrast"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/export"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/trace"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/wide"
 )
 
-var (
-	previewBorderStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("63")).
-				Padding(1, 2)
-
-	previewBorderFocusedStyle = lipgloss.NewStyle().
-					Border(lipgloss.RoundedBorder()).
-					BorderForeground(lipgloss.Color("205")).
-					Padding(1, 2)
-
-	previewTitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("205")).
-				Bold(true)
-
-	emptyStateStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Italic(true)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Italic(true)
+const (
+	// fileTimestamp is the time format used in the names of saved files
+	fileTimestamp = "20060102-150405"
+	// traceSuffix is the extension of saved trace files
+	traceSuffix = ".trace.json"
+	// filesDisabledStatus is shown when a tool that uses files is turned off
+	filesDisabledStatus = "Recordings, traces and profiles are not saved in this session"
+	// defaultHistoryLimit is the number of component states kept for time
+	// travel unless configured otherwise
+	defaultHistoryLimit = 500
 )
 
 // PreviewModel handles the component preview area
@@ -39,6 +39,52 @@ type PreviewModel struct {
 	width         int
 	height        int
 	focused       bool
+
+	// loadID changes every time a component is loaded, so results of
+	// commands issued by a previous component can be told apart
+	loadID int
+	// updates counts the messages the component has handled
+	updates int
+	// frame is the component's view, rendered once per change of state
+	frame string
+
+	// recorder captures frames while recording is active
+	recorder *export.CastRecorder
+	// trace captures every message while tracing is active
+	trace *trace.Trace
+	// history keeps earlier states of the component for time travel
+	history *timeline
+	// profile times the component's Update and View calls
+	profile      *profiler
+	showProfiler bool
+	// diff highlights cells that changed between renders
+	diff *differ
+	// audit flags text with too little contrast
+	audit *contrastAudit
+	// linter checks the view against the size the component was given
+	linter *layoutLinter
+	// widths simulates a terminal with East Asian width rules
+	widths *wideSimulation
+	// sweep animates the size the component is given, while it runs
+	sweep     *sizeSweep
+	sweepRuns int
+	// intercept holds the component's commands until they are run by hand
+	intercept *interceptor
+	// filesDisabled turns off recordings, traces and saved profiles
+	filesDisabled bool
+	// paused holds the results of commands that completed during time
+	// travel, to be delivered once the preview is live again
+	paused []componentMsg
+	// toldFocused is the focus state the component was last told about
+	toldFocused bool
+	// broadcast marks a preview that receives the same keys as another
+	broadcast bool
+	// status is a short message shown under the title
+	status string
+	// prompt replaces the help line while the console is open
+	prompt string
+
+	styles *styles
 }
 
 // NewPreviewModel creates a new preview model
@@ -46,29 +92,136 @@ func NewPreviewModel() *PreviewModel {
 	return &PreviewModel{
 		hasComponent: false,
 		focused:      false,
+		history:      newTimeline(defaultHistoryLimit),
+		profile:      newProfiler(defaultSlowThreshold),
+		diff:         newDiffer(),
+		audit:        newContrastAudit(nil),
+		linter:       &layoutLinter{},
+		widths:       &wideSimulation{},
+		intercept:    &interceptor{},
+		styles:       defaultStyles,
 	}
 }
 
-// SetSize updates the dimensions
-func (m *PreviewModel) SetSize(width, height int) {
+// SetSlowThreshold sets the p95 Update or View duration above which the
+// component is considered slow. Zero or less disables the warning.
+func (m *PreviewModel) SetSlowThreshold(threshold time.Duration) {
+	m.profile.threshold = threshold
+}
+
+// SetBackground sets the background assumed behind components that do not
+// set one when auditing contrast. Nil keeps the default.
+func (m *PreviewModel) SetBackground(background color.Color) {
+	if background != nil {
+		m.audit.options = contrast.OnBackground(background)
+	}
+}
+
+// SetHistoryLimit sets how many component states are kept for time travel.
+// A limit of zero or less disables the history.
+func (m *PreviewModel) SetHistoryLimit(limit int) {
+	m.history = newTimeline(limit)
+	if m.hasComponent && m.component != nil {
+		m.history.reset(m.component)
+	}
+}
+
+// SetFilesDisabled turns the tools that write files on or off
+func (m *PreviewModel) SetFilesDisabled(disabled bool) {
+	m.filesDisabled = disabled
+}
+
+// SetSize updates the dimensions and resizes the active component,
+// returning the command it responds with
+func (m *PreviewModel) SetSize(width, height int) tea.Cmd {
 	m.width = width
 	m.height = height
+	// A sweep up to the old size is stopped
+	m.sweep = nil
 
 	// Forward resize to component if active
 	if m.hasComponent && m.component != nil {
-		msg := tea.WindowSizeMsg{
-			Width:  width - 4, // Account for padding
-			Height: height - 4,
-		}
-		var cmd tea.Cmd
-		m.component, cmd = m.component.Update(msg)
-		_ = cmd // We'll handle this in the main update loop
+		return m.update(m.componentSize(), trace.SourceInput)
 	}
+	return nil
 }
 
-// SetFocused sets the focus state
-func (m *PreviewModel) SetFocused(focused bool) {
+// componentSize returns the size available to the component
+func (m *PreviewModel) componentSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{
+		Width:  m.width - 4, // Account for padding
+		Height: m.height - 4,
+	}
+}
+
+// givenSize returns the size the component was last sent, which is smaller
+// than the size available while a sweep runs
+func (m *PreviewModel) givenSize() tea.WindowSizeMsg {
+	if m.sweep != nil {
+		return m.sweep.current()
+	}
+	return m.componentSize()
+}
+
+// SetFocused sets the focus state. When it changes, the component is told,
+// returning the command it responds with.
+func (m *PreviewModel) SetFocused(focused bool) tea.Cmd {
+	if focused == m.focused {
+		return nil
+	}
 	m.focused = focused
+	return m.tellFocus()
+}
+
+// tellFocus calls the component's Focus or Blur method, if it has one, and
+// then sends it a tea.FocusMsg or tea.BlurMsg. During time travel the
+// component is told once it is live again.
+func (m *PreviewModel) tellFocus() tea.Cmd {
+	if !m.hasComponent || m.component == nil || m.history.scrubbing {
+		return nil
+	}
+	m.toldFocused = m.focused
+
+	if !m.focused {
+		m.callFocusMethod("Blur")
+		return m.update(tea.BlurMsg{}, trace.SourceInput)
+	}
+
+	cmd := m.issue(m.callFocusMethod("Focus"), "Focus")
+	return tea.Batch(cmd, m.update(tea.FocusMsg{}, trace.SourceInput))
+}
+
+// callFocusMethod calls a Focus or Blur method that takes nothing and
+// returns nothing or a tea.Cmd, such as those of bubbles' textinput.Model.
+// A component stored as a value is called through an addressable copy,
+// which then replaces it, so methods with pointer receivers take effect too.
+func (m *PreviewModel) callFocusMethod(name string) tea.Cmd {
+	target := reflect.ValueOf(m.component)
+	copied := target.Kind() != reflect.Pointer
+	if copied {
+		v := target
+		target = reflect.New(v.Type())
+		target.Elem().Set(v)
+	}
+
+	method := target.MethodByName(name)
+	if !method.IsValid() {
+		return nil
+	}
+	t := method.Type()
+	if t.NumIn() != 0 || t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != cmdType) {
+		return nil
+	}
+
+	out := method.Call(nil)
+	if copied {
+		m.component = target.Elem().Interface().(tea.Model)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	cmd, _ := out[0].Interface().(tea.Cmd)
+	return cmd
 }
 
 // HasComponent returns whether a component is loaded
@@ -76,24 +229,74 @@ func (m *PreviewModel) HasComponent() bool {
 	return m.hasComponent
 }
 
+// SetStatus sets the message shown under the title
+func (m *PreviewModel) SetStatus(status string) {
+	m.status = status
+}
+
+// SetPrompt shows a prompt in place of the help line, or the help line
+// again when it is empty
+func (m *PreviewModel) SetPrompt(prompt string) {
+	m.prompt = prompt
+}
+
+// SetBroadcast marks the preview as receiving keys sent to another one
+func (m *PreviewModel) SetBroadcast(broadcast bool) {
+	m.broadcast = broadcast
+}
+
 // LoadComponent loads a new component into the preview
 func (m *PreviewModel) LoadComponent(component tea.Model, name string) tea.Cmd {
+	return m.load(component, name, false)
+}
+
+// LoadComponentTraced loads a new component into the preview and traces
+// every message it receives from the start
+func (m *PreviewModel) LoadComponentTraced(component tea.Model, name string) tea.Cmd {
+	if m.filesDisabled {
+		m.status = filesDisabledStatus
+		return nil
+	}
+	return m.load(component, name, true)
+}
+
+// load replaces the active component
+func (m *PreviewModel) load(component tea.Model, name string, traced bool) tea.Cmd {
+	m.status = ""
+	m.stopRecordings()
+
+	m.loadID = nextLoadID()
 	m.component = component
 	m.componentName = name
 	m.hasComponent = true
 
+	if traced {
+		m.trace = trace.New(name, time.Now())
+		m.status = "Tracing started"
+	}
+
 	// Initialize the component
 	if m.component != nil {
+		m.history.reset(m.component)
+		m.profile.reset()
+		m.diff.reset()
+		m.linter.reset()
+		m.sweep = nil
+		m.intercept.release()
+		m.paused = nil
+		m.toldFocused = false
+
 		// Send initial window size
-		msg := tea.WindowSizeMsg{
-			Width:  m.width - 4,
-			Height: m.height - 4,
-		}
-		var cmd tea.Cmd
-		m.component, cmd = m.component.Update(msg)
+		cmd := m.update(m.componentSize(), trace.SourceInput)
 
 		// Return the component's Init command
-		return tea.Batch(cmd, m.component.Init())
+		cmds := []tea.Cmd{cmd, m.issue(m.component.Init(), "Init")}
+
+		// A component loaded into the focused preview starts focused
+		if m.focused {
+			cmds = append(cmds, m.tellFocus())
+		}
+		return tea.Batch(cmds...)
 	}
 
 	return nil
@@ -105,9 +308,574 @@ func (m *PreviewModel) ForwardMessage(msg tea.Msg) tea.Cmd {
 		return nil
 	}
 
+	// While scrubbing, input drives the timeline instead of the component
+	if m.history.scrubbing {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if resume := m.history.update(msg); resume != nil {
+				m.component = resume
+				m.updates++
+				m.status = "Resumed from an earlier state"
+				m.render()
+				return m.goLive()
+			}
+			m.audit.check(m.ComponentView())
+			return nil
+		case tea.MouseMsg:
+			return nil
+		}
+	}
+
+	return m.update(msg, trace.SourceInput)
+}
+
+// handleCommandResult delivers the result of one of the component's
+// commands. Results of commands issued by a replaced component are dropped.
+func (m *PreviewModel) handleCommandResult(msg componentMsg) tea.Cmd {
+	if !m.hasComponent || m.component == nil || msg.id != m.loadID {
+		return nil
+	}
+	if m.history.scrubbing {
+		// The state on screen is frozen until time travel ends
+		m.paused = append(m.paused, msg)
+		return nil
+	}
+	if msg.held {
+		return m.deliverHeld(msg.msg)
+	}
+
+	return m.update(msg.msg, trace.SourceCommand)
+}
+
+// deliverHeld delivers the result of an intercepted command that was run.
+// The commands of a batch or sequence are held in turn, and messages meant
+// for the program are passed on to it.
+func (m *PreviewModel) deliverHeld(msg tea.Msg) tea.Cmd {
+	if msg == nil {
+		return nil
+	}
+	if cmds, ok := cmdSlice(msg); ok {
+		if !m.intercept.enabled {
+			// Interception stopped while the command ran
+			id := m.loadID
+			return func() tea.Msg { return tagMsg(id, msg) }
+		}
+		for _, cmd := range cmds {
+			if cmd != nil {
+				m.intercept.hold(cmd, "batch")
+			}
+		}
+		return nil
+	}
+	if forProgram(msg) {
+		return func() tea.Msg { return msg }
+	}
+	return m.update(msg, trace.SourceCommand)
+}
+
+// issue tags a command the component returned, or holds it while commands
+// are intercepted
+func (m *PreviewModel) issue(cmd tea.Cmd, cause string) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	if m.intercept.enabled {
+		m.intercept.hold(cmd, cause)
+		return nil
+	}
+	return tagCmd(m.loadID, cmd)
+}
+
+// causeName describes the message a command was returned for
+func causeName(msg tea.Msg) string {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		return "key " + key.String()
+	}
+	return fmt.Sprintf("%T", msg)
+}
+
+// update sends a message to the component, keeping any recordings up to
+// date, and tags the command it returns
+func (m *PreviewModel) update(msg tea.Msg, source trace.Source) tea.Cmd {
+	if m.trace != nil {
+		if err := m.trace.Add(time.Now(), source, msg); err != nil {
+			m.status = fmt.Sprintf("Trace incomplete: %v", err)
+		}
+	}
+
 	var cmd tea.Cmd
+	start := time.Now()
 	m.component, cmd = m.component.Update(msg)
-	return cmd
+	m.profile.observeUpdate(start, time.Since(start), msg)
+	m.updates++
+	m.history.push(m.component, msg)
+	m.render()
+	return m.issue(cmd, causeName(msg))
+}
+
+// InspectedModel returns the model currently on screen, together with the
+// load it belongs to and a version that changes whenever the state does
+func (m *PreviewModel) InspectedModel() (model tea.Model, load, version int) {
+	if !m.hasComponent {
+		return nil, m.loadID, 0
+	}
+	if m.history.scrubbing {
+		// Negative versions identify states on the timeline
+		return m.history.current().model, m.loadID, -2 - m.history.dropped - m.history.cursor
+	}
+	return m.component, m.loadID, m.updates
+}
+
+// IsSlow returns whether the active component's Update or View calls are
+// slower than the warning threshold
+func (m *PreviewModel) IsSlow() bool {
+	return m.hasComponent && m.profile.slow()
+}
+
+// Lint checks the component's view, as the preview shows it, against the
+// size the component was given. It runs after every update rather than in
+// View, so the sidebar badge never lags behind the preview.
+func (m *PreviewModel) Lint() {
+	m.LintAt(m.givenSize())
+}
+
+// LintAt checks the component's view against a size it was given from
+// outside the preview, such as the whole terminal in isolated mode
+func (m *PreviewModel) LintAt(size tea.WindowSizeMsg) {
+	if !m.hasComponent || m.component == nil {
+		return
+	}
+	view := m.ComponentView()
+	if m.widths.enabled {
+		view = m.widths.render(view)
+	}
+	m.linter.check(view, size)
+}
+
+// LayoutWarnings returns how many kinds of layout mistakes were found in
+// the component's view when it was last linted
+func (m *PreviewModel) LayoutWarnings() int {
+	if !m.hasComponent {
+		return 0
+	}
+	return len(m.linter.warnings)
+}
+
+// ToggleProfiler shows the profiler overlay, or hides it and writes the
+// timings collected while it was shown to a CSV file in the working
+// directory
+func (m *PreviewModel) ToggleProfiler() {
+	if !m.showProfiler {
+		m.showProfiler = true
+		if !m.filesDisabled {
+			m.profile.startRecording()
+		}
+		return
+	}
+
+	m.showProfiler = false
+	if m.filesDisabled {
+		return
+	}
+	path := fmt.Sprintf("%s-%s.profile.csv", export.Slug(m.componentName), time.Now().Format(fileTimestamp))
+	count, err := m.profile.stopRecording(path)
+	if err != nil {
+		m.status = fmt.Sprintf("Saving profile failed: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %d timings to %s", count, path)
+}
+
+// ToggleDiff switches highlighting of changed cells on or off
+func (m *PreviewModel) ToggleDiff() tea.Cmd {
+	m.diff.toggle()
+	return m.nextDiffTick()
+}
+
+// ToggleContrast switches flagging of text with too little contrast on or
+// off
+func (m *PreviewModel) ToggleContrast() {
+	m.audit.toggle()
+	m.audit.check(m.ComponentView())
+}
+
+// Inject sends messages typed into the console to the component, in order
+func (m *PreviewModel) Inject(msgs []tea.Msg, line string) tea.Cmd {
+	if !m.hasComponent || m.component == nil || m.history.scrubbing {
+		return nil
+	}
+
+	cmds := make([]tea.Cmd, len(msgs))
+	for i, msg := range msgs {
+		cmds[i] = m.update(msg, trace.SourceInput)
+	}
+	m.status = fmt.Sprintf("Sent %s", line)
+	return tea.Batch(cmds...)
+}
+
+// ToggleWideSimulation switches between drawing the component as most
+// terminals do and as terminals with East Asian width rules do
+func (m *PreviewModel) ToggleWideSimulation() {
+	m.widths.toggle()
+}
+
+// PasteStressText pastes the next of wide.StressTexts into the component,
+// as if the user had pasted it into the terminal. Stories have no knobs to
+// swap the text into, so it only reaches fields that take input.
+func (m *PreviewModel) PasteStressText() tea.Cmd {
+	if !m.hasComponent || m.component == nil || m.history.scrubbing {
+		return nil
+	}
+	text, i := m.widths.nextStress()
+	m.status = fmt.Sprintf("Pasted stress text %d of %d: %s", i+1, len(wide.StressTexts), text.Name)
+	return m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text.Text), Paste: true}, trace.SourceInput)
+}
+
+// ToggleSweep starts animating the size the component is given from small
+// up to the size available, so its layout can be watched as it reflows, or
+// stops the animation
+func (m *PreviewModel) ToggleSweep() tea.Cmd {
+	if !m.hasComponent || m.component == nil || m.history.scrubbing {
+		return nil
+	}
+	if m.sweep != nil {
+		m.sweep = nil
+		m.status = "Sweep stopped"
+		return m.update(m.componentSize(), trace.SourceInput)
+	}
+
+	sweep := newSizeSweep(m.componentSize(), m.sweepRuns)
+	if len(sweep.sizes) == 0 {
+		return nil
+	}
+	m.sweepRuns++
+	m.sweep = sweep
+	m.status = ""
+	return tea.Batch(m.update(sweep.current(), trace.SourceInput), sweep.tick(m.loadID))
+}
+
+// advanceSweep gives the component the next size of the sweep, and the
+// size available once the sweep is done
+func (m *PreviewModel) advanceSweep(msg sweepTickMsg) tea.Cmd {
+	if m.sweep == nil || msg.load != m.loadID || msg.run != m.sweep.run {
+		return nil
+	}
+	if m.history.scrubbing {
+		// Wait until time travel ends
+		return m.sweep.tick(m.loadID)
+	}
+
+	m.sweep.index++
+	if m.sweep.index == len(m.sweep.sizes) {
+		m.sweep = nil
+		m.status = "Sweep finished"
+		return m.update(m.componentSize(), trace.SourceInput)
+	}
+	return tea.Batch(m.update(m.sweep.current(), trace.SourceInput), m.sweep.tick(m.loadID))
+}
+
+// IsIntercepting returns whether the component's commands are held
+func (m *PreviewModel) IsIntercepting() bool {
+	return m.intercept.enabled
+}
+
+// HeldCommands returns how many of the component's commands are held
+func (m *PreviewModel) HeldCommands() int {
+	return len(m.intercept.held)
+}
+
+// ToggleIntercept starts holding the commands the component returns, or
+// stops and runs every command still held
+func (m *PreviewModel) ToggleIntercept() tea.Cmd {
+	if !m.intercept.enabled {
+		m.intercept.enabled = true
+		m.status = "Intercepting commands"
+		return nil
+	}
+
+	m.intercept.enabled = false
+	held := m.intercept.release()
+	cmds := make([]tea.Cmd, len(held))
+	for i, h := range held {
+		cmds[i] = tagCmd(m.loadID, h.cmd)
+	}
+	m.status = fmt.Sprintf("Released %d held commands", len(held))
+	return tea.Batch(cmds...)
+}
+
+// SelectNextHeld selects the next held command
+func (m *PreviewModel) SelectNextHeld() {
+	m.intercept.next()
+}
+
+// RunHeld runs the selected held command. Its result is delivered as
+// usual, and any commands the component returns for it are held again.
+func (m *PreviewModel) RunHeld() tea.Cmd {
+	return m.runHeld(0)
+}
+
+// DelayHeld runs the selected held command after interceptDelay, as if it
+// were slow to complete
+func (m *PreviewModel) DelayHeld() tea.Cmd {
+	return m.runHeld(interceptDelay)
+}
+
+// runHeld runs the selected held command after a delay
+func (m *PreviewModel) runHeld(delay time.Duration) tea.Cmd {
+	held, ok := m.intercept.take()
+	if !ok {
+		return nil
+	}
+	if delay > 0 {
+		m.status = fmt.Sprintf("Running %s in %s", held.name, delay)
+	} else {
+		m.status = fmt.Sprintf("Ran %s", held.name)
+	}
+
+	id := m.loadID
+	return func() tea.Msg {
+		time.Sleep(delay)
+		return componentMsg{id: id, msg: held.cmd(), held: true}
+	}
+}
+
+// DropHeld discards the selected held command, as if it never completed
+func (m *PreviewModel) DropHeld() {
+	if held, ok := m.intercept.take(); ok {
+		m.status = fmt.Sprintf("Dropped %s", held.name)
+	}
+}
+
+// ResolveHeld discards the selected held command and delivers substitute
+// messages in place of its result, such as a story's preset
+func (m *PreviewModel) ResolveHeld(msgs []tea.Msg, label string) tea.Cmd {
+	held, ok := m.intercept.take()
+	if !ok {
+		return nil
+	}
+	cmds := make([]tea.Cmd, len(msgs))
+	for i, msg := range msgs {
+		cmds[i] = m.update(msg, trace.SourceCommand)
+	}
+	m.status = fmt.Sprintf("Resolved %s with %s", held.name, label)
+	return tea.Batch(cmds...)
+}
+
+// nextDiffTick keeps the preview redrawing while diff mode is on, so that
+// highlights fade even when the component is idle
+func (m *PreviewModel) nextDiffTick() tea.Cmd {
+	if !m.diff.enabled {
+		return nil
+	}
+	return diffTick(m.diff.run)
+}
+
+// advanceDiff schedules the next redraw for a tick of the current run of
+// diff mode, and lets ticks of earlier runs lapse
+func (m *PreviewModel) advanceDiff(msg diffTickMsg) tea.Cmd {
+	if msg.run != m.diff.run {
+		return nil
+	}
+	return m.nextDiffTick()
+}
+
+// IsTimeTravelling returns whether the preview shows an earlier state
+func (m *PreviewModel) IsTimeTravelling() bool {
+	return m.history.scrubbing
+}
+
+// ToggleTimeTravel freezes the preview so earlier states of the component
+// can be browsed, or returns to the latest state, delivering the results
+// of commands that completed in the meantime
+func (m *PreviewModel) ToggleTimeTravel() tea.Cmd {
+	if !m.hasComponent || m.component == nil {
+		return nil
+	}
+
+	if m.history.scrubbing {
+		m.history.stopScrubbing()
+		m.status = ""
+		m.audit.check(m.ComponentView())
+		return m.goLive()
+	}
+	if !m.history.startScrubbing() {
+		m.status = "Time travel is disabled"
+		return nil
+	}
+	if m.history.shared() {
+		m.status = "Registered as a pointer, so every step shows the latest state"
+	}
+	m.audit.check(m.ComponentView())
+	return nil
+}
+
+// goLive delivers the command results held back during time travel, and
+// tells the component about focus changes it missed
+func (m *PreviewModel) goLive() tea.Cmd {
+	paused := m.paused
+	m.paused = nil
+	cmds := make([]tea.Cmd, 0, len(paused)+1)
+	for _, msg := range paused {
+		cmds = append(cmds, m.handleCommandResult(msg))
+	}
+	if m.focused != m.toldFocused {
+		cmds = append(cmds, m.tellFocus())
+	}
+	return tea.Batch(cmds...)
+}
+
+// stopRecordings saves any recording or trace in progress
+func (m *PreviewModel) stopRecordings() {
+	if m.recorder != nil {
+		m.ToggleRecording()
+	}
+	if m.trace != nil {
+		m.StopTrace()
+	}
+}
+
+// IsTracing returns whether messages are being traced
+func (m *PreviewModel) IsTracing() bool {
+	return m.trace != nil
+}
+
+// StopTrace stops tracing and writes the trace to a JSON file in the
+// working directory
+func (m *PreviewModel) StopTrace() {
+	t := m.trace
+	m.trace = nil
+	if t == nil {
+		return
+	}
+
+	path := fmt.Sprintf("%s-%s%s", export.Slug(t.Story), time.Now().Format(fileTimestamp), traceSuffix)
+	if err := t.Save(path); err != nil {
+		m.status = fmt.Sprintf("Saving trace failed: %v", err)
+		return
+	}
+	if !t.Complete() {
+		m.status = fmt.Sprintf("Saved incomplete trace of %d messages to %s, which cannot be replayed", len(t.Entries), path)
+		return
+	}
+	m.status = fmt.Sprintf("Saved trace of %d messages to %s", len(t.Entries), path)
+}
+
+// Replay loads a fresh component and feeds it every message of a trace in
+// order. The component's own commands are discarded, since their results
+// are part of the trace. Afterwards the component is given the preview's
+// current size and focus, which may differ from those it was recorded
+// with, returning the commands it responds with.
+func (m *PreviewModel) Replay(component tea.Model, t *trace.Trace, source string) tea.Cmd {
+	m.status = ""
+	m.stopRecordings()
+
+	m.loadID = nextLoadID()
+	m.component = component
+	m.componentName = t.Story
+	m.hasComponent = true
+	m.history.reset(m.component)
+	m.profile.reset()
+	m.diff.reset()
+	m.linter.reset()
+	m.sweep = nil
+	m.intercept.release()
+	m.paused = nil
+	m.toldFocused = false
+
+	for _, msg := range t.Messages() {
+		m.component, _ = m.component.Update(msg)
+		m.updates++
+		m.history.push(m.component, msg)
+	}
+
+	cmds := []tea.Cmd{m.update(m.componentSize(), trace.SourceInput)}
+	if m.focused {
+		cmds = append(cmds, m.tellFocus())
+	}
+
+	m.status = fmt.Sprintf("Replayed %d messages from %s", len(t.Entries)-t.Skipped, source)
+	if t.Skipped > 0 {
+		m.status += fmt.Sprintf(", skipping %d internal ones", t.Skipped)
+	}
+	return tea.Batch(cmds...)
+}
+
+// IsRecording returns whether frames are being recorded
+func (m *PreviewModel) IsRecording() bool {
+	return m.recorder != nil
+}
+
+// ToggleRecording starts recording the component's frames, or stops and
+// writes the recording to an asciicast file in the working directory
+func (m *PreviewModel) ToggleRecording() {
+	if m.recorder == nil {
+		if !m.hasComponent || m.component == nil {
+			return
+		}
+		if m.filesDisabled {
+			m.status = filesDisabledStatus
+			return
+		}
+		size := m.componentSize()
+		m.recorder = export.NewCastRecorder(size.Width, size.Height, m.componentName, time.Now())
+		m.recordFrame()
+		m.status = "Recording started"
+		return
+	}
+
+	recorder := m.recorder
+	m.recorder = nil
+
+	path := fmt.Sprintf("%s-%s.cast", export.Slug(m.componentName), time.Now().Format(fileTimestamp))
+	if err := writeCast(path, recorder); err != nil {
+		m.status = fmt.Sprintf("Recording failed: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %d frames to %s", recorder.Len(), path)
+}
+
+// render draws the component after its state changed. The View call is
+// timed here rather than in View, so the profiler times one rendering per
+// state and not redraws of the book around it.
+func (m *PreviewModel) render() {
+	start := time.Now()
+	m.frame = m.component.View()
+	m.profile.observeView(start, time.Since(start), len(m.frame))
+	m.recordFrame()
+	m.audit.check(m.ComponentView())
+}
+
+// recordFrame captures the component's current view if recording
+func (m *PreviewModel) recordFrame() {
+	if m.recorder == nil || m.component == nil {
+		return
+	}
+	m.recorder.Frame(time.Now(), m.frame)
+}
+
+// writeCast writes a recording to a new file
+func writeCast(path string, recorder *export.CastRecorder) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := recorder.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ComponentView renders the component alone, as it would look as the root
+// model of a program
+func (m *PreviewModel) ComponentView() string {
+	if !m.hasComponent || m.component == nil {
+		return ""
+	}
+	if m.history.scrubbing {
+		return m.history.current().model.View()
+	}
+	return m.frame
 }
 
 // View renders the preview area
@@ -119,42 +887,138 @@ func (m PreviewModel) View() string {
 	var content string
 
 	if m.hasComponent && m.component != nil {
-		// Render the active component
-		componentView := m.component.View()
+		// Render the active component, or the state picked on the timeline
+		componentView := m.frame
+		if m.history.scrubbing {
+			componentView = m.history.current().model.View()
+		}
+		if m.audit.enabled {
+			componentView = m.audit.render(componentView)
+		}
+		if m.widths.enabled {
+			componentView = m.widths.render(componentView)
+		}
+		if m.diff.enabled {
+			componentView = m.diff.render(componentView, time.Now())
+		}
 
 		// Add title
-		title := previewTitleStyle.Render(m.componentName)
+		title := m.styles.previewTitle.Render(m.componentName)
+		if m.recorder != nil {
+			title += " " + m.styles.recording.Render("● REC")
+		}
+		if m.trace != nil {
+			title += " " + m.styles.recording.Render("● TRACE")
+		}
+		if m.history.scrubbing {
+			title += " " + m.styles.timelineCursor.Render("⏸ TIME TRAVEL")
+		}
+		if m.broadcast {
+			title += " " + m.styles.status.Render("⇉ BROADCAST")
+		}
+		if m.sweep != nil {
+			size := m.sweep.current()
+			title += " " + m.styles.status.Render(fmt.Sprintf("⇔ %dx%d", size.Width, size.Height))
+		}
+		if m.intercept.enabled {
+			title += " " + m.styles.status.Render(fmt.Sprintf("⧗ %d held", len(m.intercept.held)))
+		}
+		if m.diff.enabled {
+			title += " " + m.styles.status.Render(fmt.Sprintf("Δ %d cells (frame %d)", m.diff.changed, m.diff.frames))
+		}
+		if m.widths.enabled {
+			title += " " + m.styles.status.Render(fmt.Sprintf("⇹ %d ambiguous-width chars drawn wide", m.widths.widened))
+		}
+		if m.audit.enabled {
+			if len(m.audit.failing) == 0 {
+				title += " " + m.styles.status.Render("◐ contrast ok")
+			} else {
+				title += " " + m.styles.recording.Render(fmt.Sprintf("◐ %d low contrast (worst %.1f:1)", len(m.audit.failing), m.audit.worst))
+			}
+		}
 
 		// Add help text if focused
 		var help string
-		if m.focused {
-			help = helpStyle.Render("Press ESC to return to list • Press ? for help • Press q to quit")
+		if m.prompt != "" {
+			help = m.prompt
+		} else if m.history.scrubbing {
+			help = m.history.View(m.styles, m.width-4)
+		} else if m.focused {
+			help = m.styles.help.Render("Press ESC to return to list • Press ? for help • Press q to quit")
 		} else {
-			help = helpStyle.Render("Press TAB to focus preview • Press ? for help")
+			help = m.styles.help.Render("Press TAB to focus preview • Press ? for help")
 		}
 
+		// Keep the chrome on one line each in narrow panes, such as in
+		// compare mode
+		fit := lipgloss.NewStyle().MaxWidth(m.width - 4)
+
 		// Combine title, component view, and help
 		var b strings.Builder
-		b.WriteString(title)
-		b.WriteString("\n\n")
+		b.WriteString(fit.Render(title))
+		b.WriteString("\n")
+		b.WriteString(m.styles.status.MaxWidth(m.width - 4).Render(m.status))
+		b.WriteString("\n")
 		b.WriteString(componentView)
-		b.WriteString("\n\n")
-		b.WriteString(help)
+		b.WriteString("\n")
+		b.WriteString(fit.Render(m.linter.View(m.styles)))
+		b.WriteString("\n")
+		b.WriteString(fit.Render(help))
 
 		content = b.String()
 	} else {
 		// Empty state
-		content = emptyStateStyle.Render("No component selected\n\nSelect a component from the list to preview it here.")
+		content = m.styles.emptyState.Render("No component selected\n\nSelect a component from the list to preview it here.")
 	}
 
 	// Apply border
-	borderStyle := previewBorderStyle
+	borderStyle := m.styles.previewBorder
 	if m.focused {
-		borderStyle = previewBorderFocusedStyle
+		borderStyle = m.styles.previewBorderFocused
 	}
 
-	return borderStyle.
+	view := borderStyle.
 		Width(m.width).
 		Height(m.height).
 		Render(content)
+
+	// Draw the profiler in the top right corner, inside the border
+	if m.showProfiler && m.hasComponent {
+		box := m.profile.View(m.styles)
+		view = overlay(view, box, lipgloss.Width(view)-lipgloss.Width(box)-2, 1)
+	}
+
+	// Draw the held commands in the bottom left corner, inside the border
+	if m.intercept.enabled && m.hasComponent {
+		box := m.intercept.View(m.styles)
+		view = overlay(view, box, 2, lipgloss.Height(view)-lipgloss.Height(box)-1)
+	}
+
+	return view
+}
+
+// latestTrace finds the newest trace file in the working directory that
+// was saved for the given component
+func latestTrace(name string) (string, error) {
+	slug := export.Slug(name)
+	pattern := regexp.MustCompile("^" + regexp.QuoteMeta(slug) + `-\d{8}-\d{6}` + regexp.QuoteMeta(traceSuffix) + "$")
+
+	candidates, err := filepath.Glob(slug + "-*" + traceSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, path := range candidates {
+		if pattern.MatchString(path) {
+			matches = append(matches, path)
+		}
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no %s-*%s trace found in the working directory", slug, traceSuffix)
+	}
+
+	// Timestamps in the file names sort chronologically
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
 }
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/trace"
+)
+
+// probe is a story that shows the size and focus it was last given and
+// counts the keys it is sent.
+type probe struct {
+	width, height int
+	focused       bool
+	keys          int
+}
+
+func (p probe) Init() tea.Cmd { return nil }
+
+func (p probe) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+	case tea.FocusMsg:
+		p.focused = true
+	case tea.BlurMsg:
+		p.focused = false
+	case tea.KeyMsg:
+		p.keys++
+	}
+	return p, nil
+}
+
+func (p probe) View() string {
+	return fmt.Sprintf("%dx%d focused=%v keys=%d", p.width, p.height, p.focused, p.keys)
+}
+
+// newTestPreview returns a preview of the given size with a probe loaded.
+func newTestPreview(width, height int) *PreviewModel {
+	m := NewPreviewModel()
+	m.SetSize(width, height)
+	m.LoadComponent(probe{}, "Probe")
+	return m
+}
+
+func TestReplayResendsSizeAndFocus(t *testing.T) {
+	m := newTestPreview(84, 28)
+	m.SetFocused(true)
+
+	start := time.Now()
+	tr := trace.New("Probe", start)
+	tr.Add(start, trace.SourceInput, tea.WindowSizeMsg{Width: 40, Height: 10})
+	tr.Add(start, trace.SourceInput, tea.BlurMsg{})
+	tr.Add(start, trace.SourceInput, tea.KeyMsg{Type: tea.KeyUp})
+
+	m.Replay(probe{}, tr, "probe.trace.json")
+	if got, want := m.ComponentView(), "80x24 focused=true keys=1"; got != want {
+		t.Errorf("after replay the story shows %q, want %q", got, want)
+	}
+	if got := len(m.history.entries); got != 6 {
+		t.Errorf("the timeline holds %d states, want the fresh story, 3 replayed messages, the size and the focus", got)
+	}
+}
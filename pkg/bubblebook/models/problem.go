@@ -0,0 +1,95 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ProblemModel shows why the book could not be started while watching for
+// source changes, such as the compiler output of a failed build
+type ProblemModel struct {
+	title  string
+	lines  []string
+	offset int
+	width  int
+	height int
+	styles *styles
+}
+
+// NewProblemModel creates a panel with a title and the output to show
+func NewProblemModel(title, output string) ProblemModel {
+	output = strings.ReplaceAll(strings.TrimRight(output, "\n"), "\t", "    ")
+	return ProblemModel{
+		title:  title,
+		lines:  strings.Split(output, "\n"),
+		styles: defaultStyles,
+	}
+}
+
+// Init initializes the model
+func (m ProblemModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update scrolls the output and quits on q
+func (m ProblemModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "up", "k":
+			m.offset--
+		case "down", "j":
+			m.offset++
+		case "pgup":
+			m.offset -= m.visibleLines()
+		case "pgdown":
+			m.offset += m.visibleLines()
+		case "home", "g":
+			m.offset = 0
+		case "end", "G":
+			m.offset = len(m.lines)
+		}
+	}
+
+	m.offset = min(max(m.offset, 0), max(len(m.lines)-m.visibleLines(), 0))
+	return m, nil
+}
+
+// visibleLines returns how many lines of output fit on screen
+func (m ProblemModel) visibleLines() int {
+	// Border, padding, title and help
+	return max(m.height-8, 1)
+}
+
+// View renders the panel
+func (m ProblemModel) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	end := min(m.offset+m.visibleLines(), len(m.lines))
+	lines := make([]string, 0, end-m.offset)
+	for _, line := range m.lines[m.offset:end] {
+		lines = append(lines, ansi.Truncate(line, m.width-8, "…"))
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.problemTitle.Render(m.title))
+	b.WriteString("\n\n")
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.help.Render("Waiting for changes to rebuild • ↑/↓ scroll • q quit"))
+
+	return m.styles.problemBorder.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(b.String())
+}
@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// profileWindow is the number of recent calls the statistics cover
+	profileWindow = 300
+	// defaultSlowThreshold is the p95 duration above which a component is
+	// marked as slow, one frame at 60 frames per second
+	defaultSlowThreshold = 16 * time.Millisecond
+)
+
+// profileSample is a single timed call of Update or View
+type profileSample struct {
+	at       time.Time
+	call     string
+	duration time.Duration
+	// detail is the message type for Update and the output size for View
+	detail string
+}
+
+// durationWindow holds the most recent profileWindow durations, both in
+// the order they were observed and sorted, so percentiles can be read
+// without sorting on every message
+type durationWindow struct {
+	values []time.Duration
+	sorted []time.Duration
+}
+
+// add observes a duration, dropping the oldest one when the window is full
+func (w *durationWindow) add(d time.Duration) {
+	w.values = append(w.values, d)
+	i, _ := slices.BinarySearch(w.sorted, d)
+	w.sorted = slices.Insert(w.sorted, i, d)
+
+	if len(w.values) > profileWindow {
+		oldest := w.values[0]
+		w.values = append(w.values[:0], w.values[1:]...)
+		i, _ := slices.BinarySearch(w.sorted, oldest)
+		w.sorted = slices.Delete(w.sorted, i, i+1)
+	}
+}
+
+// reset empties the window
+func (w *durationWindow) reset() {
+	w.values = w.values[:0]
+	w.sorted = w.sorted[:0]
+}
+
+// percentile returns the p-th percentile using nearest rank
+func (w *durationWindow) percentile(p int) time.Duration {
+	if len(w.sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(w.sorted) + 99) / 100
+	return w.sorted[min(max(rank-1, 0), len(w.sorted)-1)]
+}
+
+// profiler times the Update and View calls of the active component
+type profiler struct {
+	threshold time.Duration
+
+	updates durationWindow
+	views   durationWindow
+	// updateTimes is used for the message rate. The component's view is
+	// rendered once per message, so a frame rate would only repeat it.
+	updateTimes []time.Time
+	viewBytes   int
+
+	// samples holds every call since the overlay was opened, for export
+	recording bool
+	samples   []profileSample
+}
+
+// newProfiler creates a profiler with the given slow threshold
+func newProfiler(threshold time.Duration) *profiler {
+	return &profiler{threshold: threshold}
+}
+
+// reset clears the statistics when another component is loaded
+func (p *profiler) reset() {
+	p.updates.reset()
+	p.views.reset()
+	p.updateTimes = p.updateTimes[:0]
+	p.viewBytes = 0
+}
+
+// observeUpdate records how long an Update call took
+func (p *profiler) observeUpdate(start time.Time, d time.Duration, msg any) {
+	p.updates.add(d)
+	p.updateTimes = appendWindow(p.updateTimes, start)
+	if p.recording {
+		p.samples = append(p.samples, profileSample{at: start, call: "update", duration: d, detail: fmt.Sprintf("%T", msg)})
+	}
+}
+
+// observeView records how long a View call took and how large its output was
+func (p *profiler) observeView(start time.Time, d time.Duration, bytes int) {
+	p.views.add(d)
+	p.viewBytes = bytes
+	if p.recording {
+		p.samples = append(p.samples, profileSample{at: start, call: "view", duration: d, detail: strconv.Itoa(bytes)})
+	}
+}
+
+// appendWindow appends a value, keeping only the most recent profileWindow
+func appendWindow[T any](values []T, v T) []T {
+	values = append(values, v)
+	if over := len(values) - profileWindow; over > 0 {
+		values = append(values[:0], values[over:]...)
+	}
+	return values
+}
+
+// slow returns whether the p95 of Update or View exceeds the threshold
+func (p *profiler) slow() bool {
+	if p.threshold <= 0 {
+		return false
+	}
+	return p.updates.percentile(95) > p.threshold || p.views.percentile(95) > p.threshold
+}
+
+// startRecording begins collecting samples for export
+func (p *profiler) startRecording() {
+	p.recording = true
+	p.samples = nil
+}
+
+// stopRecording writes the collected samples to a CSV file
+func (p *profiler) stopRecording(path string) (int, error) {
+	p.recording = false
+	samples := p.samples
+	p.samples = nil
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"time", "call", "duration_us", "detail"})
+	for _, s := range samples {
+		w.Write([]string{
+			s.at.Format(time.RFC3339Nano),
+			s.call,
+			strconv.FormatFloat(float64(s.duration)/float64(time.Microsecond), 'f', 1, 64),
+			s.detail,
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(samples), f.Close()
+}
+
+// View renders the overlay with the current statistics
+func (p *profiler) View(st *styles) string {
+	now := time.Now()
+
+	var b strings.Builder
+	b.WriteString(st.listTitle.Render("Profiler"))
+	b.WriteString("\n")
+	row := func(label, value string) {
+		b.WriteString(st.profilerLabel.Render(fmt.Sprintf("%-10s", label)) + value + "\n")
+	}
+
+	row("msgs/s", fmt.Sprintf("%.0f", perSecond(p.updateTimes, now)))
+	row("update", durationStats(st, &p.updates, p.threshold))
+	row("view", durationStats(st, &p.views, p.threshold))
+	row("view size", formatBytes(p.viewBytes))
+	if p.threshold > 0 {
+		row("slow at", "p95 > "+formatDuration(p.threshold))
+	}
+	b.WriteString(st.help.Render("f7 close and save CSV"))
+
+	return st.profilerBox.Render(b.String())
+}
+
+// durationStats formats p50, p95 and max, highlighting values over the
+// threshold
+func durationStats(st *styles, w *durationWindow, threshold time.Duration) string {
+	if len(w.sorted) == 0 {
+		return "–"
+	}
+
+	format := func(d time.Duration) string {
+		s := formatDuration(d)
+		if threshold > 0 && d > threshold {
+			return st.profilerSlow.Render(s)
+		}
+		return s
+	}
+
+	return fmt.Sprintf("p50 %s  p95 %s  max %s",
+		format(w.percentile(50)), format(w.percentile(95)), format(w.sorted[len(w.sorted)-1]))
+}
+
+// perSecond counts the events in the last second
+func perSecond(times []time.Time, now time.Time) float64 {
+	count := 0
+	for i := len(times) - 1; i >= 0 && now.Sub(times[i]) <= time.Second; i-- {
+		count++
+	}
+	return float64(count)
+}
+
+// formatDuration formats a duration with a precision suited to profiling
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	}
+}
+
+// formatBytes formats a byte count
+func formatBytes(n int) string {
+	if n >= 1024 {
+		return fmt.Sprintf("%.1f KiB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%d B", n)
+}
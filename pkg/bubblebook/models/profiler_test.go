@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDurationWindowPercentile(t *testing.T) {
+	var w durationWindow
+	for ms := 10; ms >= 1; ms-- {
+		w.add(time.Duration(ms) * time.Millisecond)
+	}
+
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{0, 1 * time.Millisecond},
+		{50, 5 * time.Millisecond},
+		{95, 10 * time.Millisecond},
+		{100, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := w.percentile(tt.p); got != tt.want {
+			t.Errorf("p%d = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestDurationWindowDropsOldest(t *testing.T) {
+	var w durationWindow
+	w.add(time.Hour)
+	for range profileWindow {
+		w.add(time.Millisecond)
+	}
+
+	if len(w.values) != profileWindow || len(w.sorted) != profileWindow {
+		t.Fatalf("the window holds %d values (%d sorted), want %d", len(w.values), len(w.sorted), profileWindow)
+	}
+	if got := w.percentile(100); got != time.Millisecond {
+		t.Errorf("max = %v, want the oldest value dropped", got)
+	}
+}
+
+func TestProfilerSlow(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		update    time.Duration
+		view      time.Duration
+		want      bool
+	}{
+		{"fast", 16 * time.Millisecond, time.Millisecond, time.Millisecond, false},
+		{"at the threshold", 16 * time.Millisecond, 16 * time.Millisecond, time.Millisecond, false},
+		{"slow update", 16 * time.Millisecond, 20 * time.Millisecond, time.Millisecond, true},
+		{"slow view", 16 * time.Millisecond, time.Millisecond, 20 * time.Millisecond, true},
+		{"disabled", 0, time.Second, time.Second, false},
+	}
+
+	for _, tt := range tests {
+		p := newProfiler(tt.threshold)
+		now := time.Now()
+		for range 20 {
+			p.observeUpdate(now, tt.update, tea.KeyMsg{})
+			p.observeView(now, tt.view, 10)
+		}
+		if got := p.slow(); got != tt.want {
+			t.Errorf("%s: slow() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProfilerExport(t *testing.T) {
+	p := newProfiler(defaultSlowThreshold)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Only calls made while recording are exported
+	p.observeUpdate(at, time.Millisecond, tea.KeyMsg{})
+	p.startRecording()
+	p.observeUpdate(at, 1500*time.Microsecond, tea.KeyMsg{})
+	p.observeView(at, 250*time.Microsecond, 42)
+
+	path := filepath.Join(t.TempDir(), "probe.profile.csv")
+	count, err := p.stopRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("%d timings were saved, want 2", count)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"time", "call", "duration_us", "detail"},
+		{"2024-05-01T12:00:00Z", "update", "1500.0", "tea.KeyMsg"},
+		{"2024-05-01T12:00:00Z", "view", "250.0", "42"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("the CSV holds %q, want %q", records, want)
+	}
+	if p.recording || p.samples != nil {
+		t.Error("the profiler is still recording after the export")
+	}
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SessionState is the part of the UI state that can be carried over to a
+// later run, so the book opens where it was left.
+//
+// Only what the user can change is kept. The sidebar lists every story
+// without collapsible groups and has a fixed width, the colours are fixed,
+// and the preview is given whatever the terminal leaves next to the
+// sidebar, so there are no expanded groups, sidebar width, theme or
+// preview size to restore. Stories have no knobs either.
+type SessionState struct {
+	// Story is the name of the selected story
+	Story string `json:"story,omitempty"`
+	// Focus is the focused pane
+	Focus Pane `json:"focus"`
+	// ListScroll is how far the sidebar is scrolled
+	ListScroll int `json:"listScroll"`
+	// Inspector is whether the model inspector is shown
+	Inspector bool `json:"inspector"`
+	// CompareStory is the story shown next to the preview in compare mode
+	CompareStory string `json:"compareStory,omitempty"`
+	// SplitPercent is the share of the preview area given to the left story
+	// in compare mode
+	SplitPercent int `json:"splitPercent,omitempty"`
+	// Broadcast is whether keys are sent to both compared stories
+	Broadcast bool `json:"broadcast,omitempty"`
+	// Gallery is whether the gallery of the selected story's group is shown
+	Gallery bool `json:"gallery,omitempty"`
+	// Presets is whether the panel of the active story's presets is shown
+	Presets bool `json:"presets,omitempty"`
+}
+
+// SessionState returns the current UI state
+func (m BubblebookModel) SessionState() SessionState {
+	state := SessionState{
+		Focus:      m.focusedPane,
+		ListScroll: m.componentList.ScrollOffset(),
+		Inspector:  m.inspector.Visible(),
+		Gallery:    m.gallery.Visible(),
+		Presets:    m.showPresets,
+	}
+	if m.selectedIndex >= 0 && m.selectedIndex < len(m.components) {
+		state.Story = m.components[m.selectedIndex].Name
+	}
+	if m.comparing {
+		state.CompareStory = m.components[m.compareIndex].Name
+		state.SplitPercent = m.splitPercent
+		state.Broadcast = m.broadcast
+	}
+	return state
+}
+
+// RestoreSession applies a saved UI state before the program starts.
+// Stories that no longer exist are skipped. The stories are started by
+// Init.
+func (m *BubblebookModel) RestoreSession(state SessionState) {
+	if index := m.storyIndex(state.Story); index >= 0 {
+		m.selectedIndex = index
+		m.componentList.Select(index)
+	}
+	m.componentList.SetScrollOffset(state.ListScroll)
+
+	if state.Inspector != m.inspector.Visible() {
+		m.inspector.Toggle()
+	}
+	m.showPresets = state.Presets
+
+	if index := m.storyIndex(state.CompareStory); index >= 0 && !state.Gallery {
+		m.comparing = true
+		m.compareIndex = index
+		if state.SplitPercent > 0 {
+			m.splitPercent = min(max(state.SplitPercent, minSplitPercent), maxSplitPercent)
+		}
+		m.broadcast = state.Broadcast
+		m.preview.SetBroadcast(m.broadcast)
+		m.compare.SetBroadcast(m.broadcast)
+	}
+	m.restoreGallery = state.Gallery && len(m.components) > 0
+
+	// Only focus panes that are shown
+	focus := state.Focus
+	switch {
+	case focus == PaneCompare && !m.comparing,
+		focus == PaneGallery && !m.restoreGallery,
+		focus == PaneInspector && !m.inspector.Visible(),
+		focus == PanePreview && m.restoreGallery:
+		focus = PaneList
+	}
+	m.setFocus(focus)
+}
+
+// ShowStory selects a story and focuses the preview before the program
+// starts. It reports false when no story has the name.
+func (m *BubblebookModel) ShowStory(name string) bool {
+	index := m.storyIndex(name)
+	if index < 0 {
+		return false
+	}
+	m.selectedIndex = index
+	m.componentList.Select(index)
+	m.restoreGallery = false
+	m.setFocus(PanePreview)
+	return true
+}
+
+// startStories loads the stories shown when the program starts
+func (m BubblebookModel) startStories() tea.Cmd {
+	if len(m.components) == 0 {
+		return nil
+	}
+
+	cmds := []tea.Cmd{m.loadComponent(m.selectedIndex)}
+	if m.comparing {
+		entry := m.components[m.compareIndex]
+		cmds = append(cmds, m.compare.LoadComponent(entry.Factory(), entry.Name))
+	}
+	if m.restoreGallery {
+		group := StoryGroup(m.components[m.selectedIndex].Name)
+		cmds = append(cmds, m.gallery.Open(group, m.components, m.selectedIndex))
+	}
+	return tea.Batch(cmds...)
+}
+
+// storyIndex returns the index of the story with the given name, or -1
+func (m BubblebookModel) storyIndex(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, entry := range m.components {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,278 @@
+package models
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// styles are the styles the book draws its own panels with. They are made
+// by a renderer, so that every remote session can draw them in the colours
+// its terminal supports.
+type styles struct {
+	// Sidebar
+	listBorder        lipgloss.Style
+	listBorderFocused lipgloss.Style
+	listTitle         lipgloss.Style
+	selectedItem      lipgloss.Style
+	normalItem        lipgloss.Style
+	cursor            lipgloss.Style
+	slowBadge         lipgloss.Style
+	more              lipgloss.Style
+
+	// Preview
+	previewBorder        lipgloss.Style
+	previewBorderFocused lipgloss.Style
+	previewTitle         lipgloss.Style
+	emptyState           lipgloss.Style
+	help                 lipgloss.Style
+	recording            lipgloss.Style
+	status               lipgloss.Style
+	layoutBadge          lipgloss.Style
+	presetBox            lipgloss.Style
+
+	// Message console
+	consolePrompt lipgloss.Style
+	consoleCursor lipgloss.Style
+	consoleError  lipgloss.Style
+
+	// Gallery
+	galleryBorder        lipgloss.Style
+	galleryBorderFocused lipgloss.Style
+	tileBorder           lipgloss.Style
+	tileBorderSelected   lipgloss.Style
+	tileCaption          lipgloss.Style
+
+	// Help screen
+	helpTitle   lipgloss.Style
+	helpSection lipgloss.Style
+	helpKey     lipgloss.Style
+	helpDesc    lipgloss.Style
+	helpBox     lipgloss.Style
+
+	// Inspector
+	inspectorBorder        lipgloss.Style
+	inspectorBorderFocused lipgloss.Style
+	inspectorName          lipgloss.Style
+	inspectorValue         lipgloss.Style
+	inspectorChanged       lipgloss.Style
+	inspectorCursor        lipgloss.Style
+
+	// Command interception
+	interceptBox      lipgloss.Style
+	interceptSelected lipgloss.Style
+
+	// Build problems shown by watch
+	problemBorder lipgloss.Style
+	problemTitle  lipgloss.Style
+
+	// Profiler
+	profilerBox   lipgloss.Style
+	profilerLabel lipgloss.Style
+	profilerSlow  lipgloss.Style
+
+	// Timeline
+	timelineTitle  lipgloss.Style
+	timelineTrack  lipgloss.Style
+	timelineCursor lipgloss.Style
+}
+
+// defaultStyles are made by lipgloss's default renderer, which draws in
+// the colours of the terminal the program runs in
+var defaultStyles = newStyles(nil)
+
+// newStyles makes the book's styles with a renderer. Nil uses lipgloss's
+// default renderer.
+func newStyles(r *lipgloss.Renderer) *styles {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+
+	return &styles{
+		listBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1),
+
+		listBorderFocused: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(0, 1),
+
+		listTitle: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true),
+
+		selectedItem: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true).
+			PaddingLeft(2),
+
+		normalItem: r.NewStyle().
+			Foreground(lipgloss.Color("246")).
+			PaddingLeft(2),
+
+		cursor: r.NewStyle().
+			Foreground(lipgloss.Color("205")),
+
+		slowBadge: r.NewStyle().
+			Foreground(lipgloss.Color("214")),
+
+		more: r.NewStyle().
+			Foreground(lipgloss.Color("241")),
+
+		previewBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(1, 2),
+
+		previewBorderFocused: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(1, 2),
+
+		previewTitle: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true),
+
+		emptyState: r.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Italic(true),
+
+		help: r.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Italic(true),
+
+		recording: r.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true),
+
+		status: r.NewStyle().
+			Foreground(lipgloss.Color("141")),
+
+		layoutBadge: r.NewStyle().
+			Foreground(lipgloss.Color("179")),
+
+		presetBox: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("141")).
+			Padding(0, 1),
+
+		consolePrompt: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true),
+
+		consoleCursor: r.NewStyle().
+			Reverse(true),
+
+		consoleError: r.NewStyle().
+			Foreground(lipgloss.Color("196")),
+
+		galleryBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1),
+
+		galleryBorderFocused: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(0, 1),
+
+		tileBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("238")),
+
+		tileBorderSelected: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")),
+
+		tileCaption: r.NewStyle().
+			Foreground(lipgloss.Color("246")),
+
+		helpTitle: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true).
+			Padding(0, 0, 1, 0),
+
+		helpSection: r.NewStyle().
+			Foreground(lipgloss.Color("141")).
+			Bold(true).
+			Padding(1, 0, 0, 0),
+
+		helpKey: r.NewStyle().
+			Foreground(lipgloss.Color("212")).
+			Bold(true),
+
+		helpDesc: r.NewStyle().
+			Foreground(lipgloss.Color("246")),
+
+		helpBox: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(2, 4).
+			Margin(1, 2),
+
+		inspectorBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1),
+
+		inspectorBorderFocused: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("205")).
+			Padding(0, 1),
+
+		inspectorName: r.NewStyle().
+			Foreground(lipgloss.Color("141")),
+
+		inspectorValue: r.NewStyle().
+			Foreground(lipgloss.Color("246")),
+
+		inspectorChanged: r.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("220")),
+
+		inspectorCursor: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true),
+
+		interceptBox: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("214")).
+			Padding(0, 1),
+
+		interceptSelected: r.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true),
+
+		problemBorder: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("196")).
+			Padding(1, 2),
+
+		problemTitle: r.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true),
+
+		profilerBox: r.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("141")).
+			Padding(0, 1),
+
+		profilerLabel: r.NewStyle().
+			Foreground(lipgloss.Color("246")),
+
+		profilerSlow: r.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true),
+
+		timelineTitle: r.NewStyle().
+			Foreground(lipgloss.Color("141")).
+			Bold(true),
+
+		timelineTrack: r.NewStyle().
+			Foreground(lipgloss.Color("241")),
+
+		timelineCursor: r.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true),
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// sweepInterval is how long each size of a sweep is shown
+	sweepInterval = 80 * time.Millisecond
+	// sweepMinWidth and sweepMinHeight are the sizes a sweep starts from
+	sweepMinWidth  = 20
+	sweepMinHeight = 5
+	// sweepWidthStep is the number of columns a sweep grows by each step
+	sweepWidthStep = 2
+)
+
+// sweepTickMsg moves a sweep on to its next size. It carries the load and
+// run it belongs to, so ticks of a stopped sweep are dropped.
+type sweepTickMsg struct {
+	load, run int
+}
+
+// sizeSweep animates the size a component is given, first growing its
+// width at the full height and then its height at the full width
+type sizeSweep struct {
+	sizes []tea.WindowSizeMsg
+	index int
+	run   int
+}
+
+// newSizeSweep creates a sweep up to the given size
+func newSizeSweep(full tea.WindowSizeMsg, run int) *sizeSweep {
+	s := &sizeSweep{run: run}
+	for w := min(sweepMinWidth, full.Width); w < full.Width; w += sweepWidthStep {
+		s.sizes = append(s.sizes, tea.WindowSizeMsg{Width: w, Height: full.Height})
+	}
+	for h := min(sweepMinHeight, full.Height); h < full.Height; h++ {
+		s.sizes = append(s.sizes, tea.WindowSizeMsg{Width: full.Width, Height: h})
+	}
+	return s
+}
+
+// current returns the size the component is given now
+func (s *sizeSweep) current() tea.WindowSizeMsg {
+	return s.sizes[s.index]
+}
+
+// tick schedules the next step of the sweep
+func (s *sizeSweep) tick(load int) tea.Cmd {
+	run := s.run
+	return tea.Tick(sweepInterval, func(time.Time) tea.Msg {
+		return sweepTickMsg{load: load, run: run}
+	})
+}
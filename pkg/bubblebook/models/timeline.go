@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyEntry is the state of a component after it handled a message
+type historyEntry struct {
+	model tea.Model
+	msg   tea.Msg
+}
+
+// timeline keeps the models a component returned from Update, so that
+// earlier states can be viewed again and resumed from
+type timeline struct {
+	entries []historyEntry
+	limit   int
+	// dropped counts entries discarded to stay within the limit, so steps
+	// keep their numbers
+	dropped   int
+	cursor    int
+	scrubbing bool
+}
+
+// newTimeline creates a timeline that keeps at most limit states. A limit
+// of zero or less disables the history.
+func newTimeline(limit int) *timeline {
+	return &timeline{limit: limit}
+}
+
+// enabled returns whether states are being kept
+func (t *timeline) enabled() bool {
+	return t.limit > 0
+}
+
+// reset starts a new history from a freshly created component
+func (t *timeline) reset(model tea.Model) {
+	t.entries = t.entries[:0]
+	t.dropped = 0
+	t.cursor = 0
+	t.scrubbing = false
+	t.push(model, nil)
+}
+
+// push records the model returned for a message
+func (t *timeline) push(model tea.Model, msg tea.Msg) {
+	if !t.enabled() {
+		return
+	}
+
+	t.entries = append(t.entries, historyEntry{model: model, msg: msg})
+	if over := len(t.entries) - t.limit; over > 0 {
+		t.entries = append(t.entries[:0], t.entries[over:]...)
+		t.dropped += over
+		t.cursor = max(t.cursor-over, 0)
+	}
+	if !t.scrubbing {
+		t.cursor = len(t.entries) - 1
+	}
+}
+
+// shared returns whether the component is a pointer, whose states all
+// alias the one model that Update changes in place
+func (t *timeline) shared() bool {
+	return len(t.entries) > 0 && reflect.ValueOf(t.entries[0].model).Kind() == reflect.Pointer
+}
+
+// current returns the entry under the cursor
+func (t *timeline) current() historyEntry {
+	return t.entries[t.cursor]
+}
+
+// startScrubbing freezes the view on the latest state
+func (t *timeline) startScrubbing() bool {
+	if !t.enabled() || len(t.entries) == 0 {
+		return false
+	}
+	t.scrubbing = true
+	t.cursor = len(t.entries) - 1
+	return true
+}
+
+// stopScrubbing returns to the live state
+func (t *timeline) stopScrubbing() {
+	t.scrubbing = false
+	t.cursor = len(t.entries) - 1
+}
+
+// move shifts the cursor by delta steps
+func (t *timeline) move(delta int) {
+	t.cursor = min(max(t.cursor+delta, 0), len(t.entries)-1)
+}
+
+// fork discards every state after the cursor and returns the model at the
+// cursor, so the component can carry on from there
+func (t *timeline) fork() tea.Model {
+	t.entries = t.entries[:t.cursor+1]
+	t.scrubbing = false
+	return t.entries[t.cursor].model
+}
+
+// update handles navigation keys while scrubbing. It returns the model to
+// resume from when the timeline is forked.
+func (t *timeline) update(msg tea.KeyMsg) (resume tea.Model) {
+	switch msg.String() {
+	case "left", "h":
+		t.move(-1)
+	case "right", "l":
+		t.move(1)
+	case "pgup":
+		t.move(-10)
+	case "pgdown":
+		t.move(10)
+	case "home", "g":
+		t.move(-len(t.entries))
+	case "end", "G":
+		t.move(len(t.entries))
+	case "enter":
+		return t.fork()
+	}
+	return nil
+}
+
+// View renders the timeline panel
+func (t *timeline) View(st *styles, width int) string {
+	if width < 10 {
+		return ""
+	}
+
+	step := t.dropped + t.cursor
+	last := t.dropped + len(t.entries) - 1
+	position := fmt.Sprintf("step %d of %d", step, last)
+	if t.cursor == len(t.entries)-1 {
+		position += " (latest)"
+	}
+
+	// Track with the cursor placed proportionally along it
+	track := make([]string, width)
+	for i := range track {
+		track[i] = st.timelineTrack.Render("─")
+	}
+	pos := 0
+	if len(t.entries) > 1 {
+		pos = t.cursor * (width - 1) / (len(t.entries) - 1)
+	}
+	track[pos] = st.timelineCursor.Render("●")
+
+	var b strings.Builder
+	b.WriteString(st.timelineTitle.Render("Timeline") + " " + st.help.Render(position))
+	b.WriteString("\n")
+	b.WriteString(strings.Join(track, ""))
+	b.WriteString("\n")
+	b.WriteString(st.status.MaxWidth(width).Render(describeMsg(t.current().msg)))
+	b.WriteString("\n")
+	b.WriteString(st.help.MaxWidth(width).Render("←/→ step • home/end • enter resume from here • f5 back to live"))
+
+	return b.String()
+}
+
+// describeMsg returns a short description of a message
+func describeMsg(msg tea.Msg) string {
+	switch msg := msg.(type) {
+	case nil:
+		return "component created"
+	case tea.KeyMsg:
+		return fmt.Sprintf("key %q", msg.String())
+	case tea.WindowSizeMsg:
+		return fmt.Sprintf("resize %dx%d", msg.Width, msg.Height)
+	case tea.MouseMsg:
+		return fmt.Sprintf("mouse %s at %d,%d", msg.String(), msg.X, msg.Y)
+	default:
+		return fmt.Sprintf("%T", msg)
+	}
+}
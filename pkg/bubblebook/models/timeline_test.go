@@ -0,0 +1,113 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keysAt returns the number of keys the probe of an entry had been sent.
+func keysAt(t *testing.T, entry historyEntry) int {
+	t.Helper()
+	p, ok := entry.model.(probe)
+	if !ok {
+		t.Fatalf("the entry holds %T, want a probe", entry.model)
+	}
+	return p.keys
+}
+
+func TestTimelineLimit(t *testing.T) {
+	tl := newTimeline(3)
+	tl.reset(probe{})
+	for keys := 1; keys <= 4; keys++ {
+		tl.push(probe{keys: keys}, tea.KeyMsg{Type: tea.KeyUp})
+	}
+
+	if len(tl.entries) != 3 || tl.dropped != 2 {
+		t.Fatalf("the timeline holds %d states with %d dropped, want 3 and 2", len(tl.entries), tl.dropped)
+	}
+	if got := keysAt(t, tl.entries[0]); got != 2 {
+		t.Errorf("the oldest state kept has %d keys, want 2", got)
+	}
+	if got := keysAt(t, tl.current()); got != 4 {
+		t.Errorf("the cursor is on a state with %d keys, want the latest", got)
+	}
+	// Steps keep their numbers after earlier ones are dropped
+	if view := tl.View(defaultStyles, 40); !strings.Contains(view, "step 4 of 4 (latest)") {
+		t.Errorf("the panel does not number the latest state 4:\n%s", view)
+	}
+}
+
+func TestTimelineLimitWhileScrubbing(t *testing.T) {
+	tl := newTimeline(3)
+	tl.reset(probe{})
+	tl.push(probe{keys: 1}, tea.KeyMsg{Type: tea.KeyUp})
+	tl.push(probe{keys: 2}, tea.KeyMsg{Type: tea.KeyUp})
+
+	tl.startScrubbing()
+	tl.move(-1)
+	tl.push(probe{keys: 3}, tea.KeyMsg{Type: tea.KeyUp})
+
+	// The cursor stays on the state being viewed as the oldest is dropped
+	if got := keysAt(t, tl.current()); got != 1 {
+		t.Errorf("the cursor moved to a state with %d keys, want 1", got)
+	}
+}
+
+func TestTimelineDisabled(t *testing.T) {
+	tl := newTimeline(0)
+	tl.reset(probe{})
+	tl.push(probe{keys: 1}, tea.KeyMsg{Type: tea.KeyUp})
+
+	if len(tl.entries) != 0 {
+		t.Errorf("a disabled timeline kept %d states", len(tl.entries))
+	}
+	if tl.startScrubbing() {
+		t.Error("a disabled timeline started scrubbing")
+	}
+}
+
+func TestTimelineFork(t *testing.T) {
+	tl := newTimeline(10)
+	tl.reset(probe{})
+	for keys := 1; keys <= 3; keys++ {
+		tl.push(probe{keys: keys}, tea.KeyMsg{Type: tea.KeyUp})
+	}
+
+	tl.startScrubbing()
+	tl.update(tea.KeyMsg{Type: tea.KeyLeft})
+	tl.update(tea.KeyMsg{Type: tea.KeyLeft})
+	resume := tl.update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if p, ok := resume.(probe); !ok || p.keys != 1 {
+		t.Errorf("resumed from %#v, want the state with 1 key", resume)
+	}
+	if len(tl.entries) != 2 {
+		t.Errorf("the timeline holds %d states after forking, want the later ones discarded", len(tl.entries))
+	}
+	if tl.scrubbing {
+		t.Error("the timeline is still scrubbing after forking")
+	}
+}
+
+func TestTimeTravelPausesCommandResults(t *testing.T) {
+	m := newTestPreview(84, 28)
+	m.ToggleTimeTravel()
+
+	m.handleCommandResult(componentMsg{id: m.loadID, msg: tea.KeyMsg{Type: tea.KeyUp}})
+	if len(m.paused) != 1 {
+		t.Fatalf("%d results were paused, want 1", len(m.paused))
+	}
+	if got, want := m.ComponentView(), "80x24 focused=false keys=0"; got != want {
+		t.Errorf("while time travelling the story shows %q, want %q", got, want)
+	}
+
+	m.ToggleTimeTravel()
+	if got, want := m.ComponentView(), "80x24 focused=false keys=1"; got != want {
+		t.Errorf("after going live the story shows %q, want %q", got, want)
+	}
+	if len(m.paused) != 0 {
+		t.Errorf("%d results are still paused after going live", len(m.paused))
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/wide"
+)
+
+// wideHighlight is the background of characters whose width depends on
+// the terminal
+var wideHighlight = ansi.IndexedColor(90)
+
+// wideSimulation draws every rendered view as a terminal with East Asian
+// width rules would, with ambiguous-width characters two cells wide
+type wideSimulation struct {
+	enabled bool
+
+	lastView string
+	rendered string
+	widened  int
+
+	// stress is the next of wide.StressTexts to paste
+	stress int
+}
+
+// toggle switches the simulation on or off
+func (s *wideSimulation) toggle() {
+	s.enabled = !s.enabled
+	s.lastView = ""
+}
+
+// render widens the ambiguous-width characters of a view and highlights
+// them
+func (s *wideSimulation) render(view string) string {
+	if view == s.lastView {
+		return s.rendered
+	}
+	s.lastView = view
+
+	buf, widened := wide.Widen(view, func(c *cellbuf.Cell) {
+		c.Style.Bg = wideHighlight
+	})
+	s.widened = widened
+	s.rendered = view
+	if widened > 0 {
+		s.rendered = strings.ReplaceAll(cellbuf.Render(buf), "\r\n", "\n")
+	}
+	return s.rendered
+}
+
+// nextStress returns the next stress text to paste, cycling through them
+func (s *wideSimulation) nextStress() (wide.StressText, int) {
+	i := s.stress % len(wide.StressTexts)
+	s.stress++
+	return wide.StressTexts[i], i
+}
@@ -0,0 +1,56 @@
+package bubblebook
+
+import (
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// newRemoteProgram creates the TUI of a remote session, reading the
+// client's keys from input and writing to output in the colours its
+// environment says it supports. The book's panels are drawn by a renderer
+// of the session's own. Stories draw with lipgloss's default renderer, as
+// they would in an app, and their colours are brought down to what the
+// client supports as they are written to it. The client's size has to be
+// sent to the program once it runs.
+func newRemoteProgram(config models.Config, input io.Reader, output io.Writer, env []string) *tea.Program {
+	profile := colorprofile.Env(env)
+	if profile == colorprofile.NoTTY {
+		// Keep the escape sequences that draw the TUI
+		profile = colorprofile.Ascii
+	}
+	config.Renderer = sessionRenderer(output, profile)
+
+	return tea.NewProgram(
+		models.NewBubblebookModelWithConfig(components, config),
+		tea.WithInput(input),
+		tea.WithOutput(&colorprofile.Writer{Forward: output, Profile: profile}),
+		tea.WithEnvironment(env),
+		tea.WithoutSignalHandler(),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+}
+
+// sessionRenderer creates a renderer that draws in the colours of a
+// client's terminal. The terminal cannot be asked for its background, so
+// it is taken to be dark.
+func sessionRenderer(output io.Writer, profile colorprofile.Profile) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(output)
+	switch profile {
+	case colorprofile.TrueColor:
+		r.SetColorProfile(termenv.TrueColor)
+	case colorprofile.ANSI256:
+		r.SetColorProfile(termenv.ANSI256)
+	case colorprofile.ANSI:
+		r.SetColorProfile(termenv.ANSI)
+	default:
+		r.SetColorProfile(termenv.Ascii)
+	}
+	r.SetHasDarkBackground(true)
+	return r
+}
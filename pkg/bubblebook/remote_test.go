@@ -0,0 +1,55 @@
+package bubblebook
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+func TestSessionRenderer(t *testing.T) {
+	withStories(t, models.ComponentEntry{
+		Name:    "Greeting",
+		Factory: func() tea.Model { return greeting{} },
+	})
+
+	// The sidebar border is colour 63 of the 256-colour palette
+	tests := []struct {
+		profile colorprofile.Profile
+		colour  bool
+		palette bool
+	}{
+		{colorprofile.TrueColor, true, true},
+		{colorprofile.ANSI256, true, true},
+		{colorprofile.ANSI, true, false},
+		{colorprofile.Ascii, false, false},
+	}
+
+	for _, tt := range tests {
+		config := models.DefaultConfig()
+		config.Renderer = sessionRenderer(io.Discard, tt.profile)
+		model, _ := models.NewBubblebookModelWithConfig(components, config).Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+		view := model.View()
+		if got := strings.Contains(view, "\x1b[3") || strings.Contains(view, "\x1b[9"); got != tt.colour {
+			t.Errorf("%v: the book is drawn in colour: %v, want %v", tt.profile, got, tt.colour)
+		}
+		if got := strings.Contains(view, "\x1b[38;5;63m"); got != tt.palette {
+			t.Errorf("%v: the sidebar border is drawn with colour 63: %v, want %v", tt.profile, got, tt.palette)
+		}
+	}
+}
+
+func TestRemoteProgramKeepsDefaultRenderer(t *testing.T) {
+	withStories(t)
+
+	profile, dark := lipgloss.ColorProfile(), lipgloss.HasDarkBackground()
+	newRemoteProgram(models.DefaultConfig(), strings.NewReader(""), io.Discard, []string{"TERM=xterm-256color", "COLORTERM=truecolor"})
+	if lipgloss.ColorProfile() != profile || lipgloss.HasDarkBackground() != dark {
+		t.Error("starting a remote session changed lipgloss's default renderer")
+	}
+}
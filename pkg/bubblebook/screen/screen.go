@@ -0,0 +1,31 @@
+package screen
+
+import (
+	"image/color"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// DefaultForeground and DefaultBackground stand for a terminal's default
+// colours, in cells that set none of their own. They are a light grey on a
+// dark grey.
+var (
+	DefaultForeground = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	DefaultBackground = color.RGBA{0x1c, 0x1c, 0x1c, 0xff}
+)
+
+// Parse lays out ANSI styled text on a cell grid. A width or height of zero
+// or less is measured from the text itself.
+func Parse(view string, width, height int) *cellbuf.Buffer {
+	if width <= 0 {
+		width = lipgloss.Width(view)
+	}
+	if height <= 0 {
+		height = lipgloss.Height(view)
+	}
+
+	buf := cellbuf.NewBuffer(max(width, 1), max(height, 1))
+	cellbuf.SetContent(buf, view)
+	return buf
+}
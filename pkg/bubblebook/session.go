@@ -0,0 +1,88 @@
+package bubblebook
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/export"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// projectStatePath returns the file the UI state of the book in the working
+// directory is kept in between runs, under the XDG state directory.
+func projectStatePath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// Books in directories with the same name get their own files
+	sum := sha256.Sum256([]byte(wd))
+	name := fmt.Sprintf("%s-%x.json", export.Slug(filepath.Base(wd)), sum[:4])
+	return filepath.Join(dir, "bubblebook", name), nil
+}
+
+// sessionPaths returns the files the UI state is restored from, in order
+// of preference, and saved to when the TUI exits.
+func sessionPaths(flags startFlags) []string {
+	var paths []string
+
+	// The state watch keeps across restarts comes first
+	if path := os.Getenv(watchStateEnv); path != "" {
+		paths = append(paths, path)
+	}
+
+	if flags.noState {
+		return paths
+	}
+	path, err := projectStatePath()
+	if err != nil {
+		return paths
+	}
+	if flags.clearState {
+		os.Remove(path)
+	}
+	return append(paths, path)
+}
+
+// clearSession deletes the UI state saved for the book in the working
+// directory.
+func clearSession() {
+	if path, err := projectStatePath(); err == nil {
+		os.Remove(path)
+	}
+}
+
+// loadSession reads a saved UI state.
+func loadSession(path string) (models.SessionState, error) {
+	var state models.SessionState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	return state, json.Unmarshal(data, &state)
+}
+
+// saveSession writes a UI state, creating its directory if needed.
+func saveSession(path string, state models.SessionState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
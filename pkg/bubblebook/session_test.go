@@ -0,0 +1,133 @@
+package bubblebook
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// stateHome makes a temporary directory the XDG state directory and
+// returns it. The state watch passes on is cleared.
+func stateHome(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	t.Setenv(watchStateEnv, "")
+	return dir
+}
+
+// projectDir creates a directory named name and makes it the working
+// directory.
+func projectDir(t *testing.T, name string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	stateHome(t)
+	projectDir(t, "book")
+
+	stories := []models.ComponentEntry{
+		{Name: "Button/Primary", Factory: func() tea.Model { return greeting{} }},
+		{Name: "Button/Danger", Factory: func() tea.Model { return greeting{} }},
+		{Name: "Input", Factory: func() tea.Model { return greeting{} }},
+	}
+	want := models.SessionState{
+		Story:        "Button/Danger",
+		Focus:        models.PaneCompare,
+		ListScroll:   1,
+		Inspector:    true,
+		CompareStory: "Input",
+		SplitPercent: 60,
+		Broadcast:    true,
+		Presets:      true,
+	}
+
+	model := models.NewBubblebookModel(stories)
+	model.RestoreSession(want)
+	paths := sessionPaths(startFlags{})
+	if len(paths) != 1 {
+		t.Fatalf("sessionPaths() = %v, want the project's state file", paths)
+	}
+	if err := saveSession(paths[0], model.SessionState()); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := loadSession(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := models.NewBubblebookModel(stories)
+	restored.RestoreSession(state)
+	if got := restored.SessionState(); !reflect.DeepEqual(got, want) {
+		t.Errorf("the restored state is %+v, want %+v", got, want)
+	}
+}
+
+func TestSessionPathPerProject(t *testing.T) {
+	home := stateHome(t)
+
+	projectDir(t, "book")
+	first, err := projectStatePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir(t, "book")
+	second, err := projectStatePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("two projects named book share the state file %s", first)
+	}
+	for _, path := range []string{first, second} {
+		if filepath.Dir(path) != filepath.Join(home, "bubblebook") || !strings.HasPrefix(filepath.Base(path), "book-") {
+			t.Errorf("the state is kept in %s, want a file named after the project in %s", path, filepath.Join(home, "bubblebook"))
+		}
+	}
+}
+
+func TestSessionFlags(t *testing.T) {
+	stateHome(t)
+	projectDir(t, "book")
+
+	path, err := projectStatePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSession(path, models.SessionState{Story: "Input"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if paths := sessionPaths(startFlags{noState: true}); len(paths) != 0 {
+		t.Errorf("--no-state uses %v", paths)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("--no-state removed the saved state: %v", err)
+	}
+
+	// The state watch keeps across restarts is preferred
+	watchState := filepath.Join(t.TempDir(), "watch.json")
+	t.Setenv(watchStateEnv, watchState)
+	if paths := sessionPaths(startFlags{}); !slices.Equal(paths, []string{watchState, path}) {
+		t.Errorf("sessionPaths() = %v, want %v", paths, []string{watchState, path})
+	}
+
+	if paths := sessionPaths(startFlags{clearState: true}); !slices.Contains(paths, path) {
+		t.Errorf("--clear-state does not save the state again: %v", paths)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("--clear-state kept the saved state (stat: %v)", err)
+	}
+}
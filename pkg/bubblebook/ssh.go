@@ -0,0 +1,399 @@
+package bubblebook
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrSSHServerClosed is returned by SSHServer.Serve after Close.
+var ErrSSHServerClosed = errors.New("bubblebook: SSH server closed")
+
+// errNoSSHAuth is returned when a server without authentication is asked to
+// serve on an address other machines can reach.
+var errNoSSHAuth = errors.New("bubblebook: SSH clients must be authenticated with WithPasswordAuth or WithPublicKeyAuth unless the server listens on a loopback address")
+
+// sshOptions holds the settings of the SSH server.
+type sshOptions struct {
+	hostKeyFile   string
+	passwordAuth  func(user, password string) bool
+	publicKeyAuth func(user string, key ssh.PublicKey) bool
+}
+
+// WithHostKeyFile makes the SSH server read its host key from a PEM file,
+// creating an ed25519 key there when the file does not exist. Without it, a
+// new key is generated every time the server starts, and clients will warn
+// that the host key changed.
+func WithHostKeyFile(path string) Option {
+	return func(o *options) {
+		o.ssh.hostKeyFile = path
+	}
+}
+
+// WithPasswordAuth lets SSH clients in when check accepts their user name
+// and password.
+func WithPasswordAuth(check func(user, password string) bool) Option {
+	return func(o *options) {
+		o.ssh.passwordAuth = check
+	}
+}
+
+// WithPublicKeyAuth lets SSH clients in when check accepts their user name
+// and public key.
+func WithPublicKeyAuth(check func(user string, key ssh.PublicKey) bool) Option {
+	return func(o *options) {
+		o.ssh.publicKeyAuth = check
+	}
+}
+
+// SSHServer serves the book to SSH clients. Every session gets its own TUI
+// with its own instances of the stories, sized to the client's terminal and
+// rendered in the colours it supports.
+type SSHServer struct {
+	// OnError, when set, is called with the errors of connections that
+	// could not be served, such as failed handshakes. Set it before Serve.
+	OnError func(error)
+
+	config *ssh.ServerConfig
+	model  models.Config
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+}
+
+// NewSSHServer creates an SSH server for the registered stories. It takes
+// the same options as Start, together with WithHostKeyFile,
+// WithPasswordAuth and WithPublicKeyAuth. Without an authentication option,
+// anyone who can reach the server is let in, so it only serves on loopback
+// addresses.
+//
+// Clients cannot record, trace or save profiles, since the files would land
+// in the server's working directory.
+func NewSSHServer(opts ...Option) (*SSHServer, error) {
+	o := options{model: models.DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newSSHServer(o)
+}
+
+// newSSHServer creates an SSH server from collected options.
+func newSSHServer(o options) (*SSHServer, error) {
+	config := &ssh.ServerConfig{}
+	if o.ssh.passwordAuth != nil {
+		check := o.ssh.passwordAuth
+		config.PasswordCallback = func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if !check(conn.User(), string(password)) {
+				return nil, fmt.Errorf("password rejected for %s", conn.User())
+			}
+			return nil, nil
+		}
+	}
+	if o.ssh.publicKeyAuth != nil {
+		check := o.ssh.publicKeyAuth
+		config.PublicKeyCallback = func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !check(conn.User(), key) {
+				return nil, fmt.Errorf("public key rejected for %s", conn.User())
+			}
+			return nil, nil
+		}
+	}
+	config.NoClientAuth = o.ssh.passwordAuth == nil && o.ssh.publicKeyAuth == nil
+
+	hostKey, err := loadHostKey(o.ssh.hostKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	config.AddHostKey(hostKey)
+
+	model := o.model
+	model.DisableFiles = true
+	return &SSHServer{
+		config:    config,
+		model:     model,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// ServeSSH serves the book to SSH clients on addr, such as ":2222" or
+// "localhost:2222" when no authentication option is given, until it fails.
+func ServeSSH(addr string, opts ...Option) error {
+	server, err := NewSSHServer(opts...)
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(l)
+}
+
+// Serve accepts connections on l until it fails or the server is closed,
+// in which case it returns ErrSSHServerClosed. A server without
+// authentication refuses listeners that are not on a loopback address.
+func (s *SSHServer) Serve(l net.Listener) error {
+	if err := s.checkListener(l); err != nil {
+		l.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrSSHServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrSSHServerClosed
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops accepting connections and ends every session.
+func (s *SSHServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// handleConn runs the SSH handshake and serves the sessions a client opens.
+func (s *SSHServer) handleConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		if s.OnError != nil {
+			s.OnError(fmt.Errorf("bubblebook: SSH handshake with %s failed: %w", conn.RemoteAddr(), err))
+		}
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+// Payloads of the session requests, as laid out in RFC 4254
+type (
+	ptyRequest struct {
+		Term    string
+		Columns uint32
+		Rows    uint32
+		Width   uint32
+		Height  uint32
+		Modes   string
+	}
+
+	windowChangeRequest struct {
+		Columns uint32
+		Rows    uint32
+		Width   uint32
+		Height  uint32
+	}
+
+	envRequest struct {
+		Name  string
+		Value string
+	}
+
+	exitStatus struct {
+		Status uint32
+	}
+)
+
+// handleSession runs a TUI in a session once the client asks for a shell.
+// The session ends when the TUI is quit or the client goes away.
+func (s *SSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	var (
+		pty     *ptyRequest
+		env     []string
+		program *tea.Program
+	)
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			var p ptyRequest
+			if err := ssh.Unmarshal(req.Payload, &p); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			pty = &p
+			req.Reply(true, nil)
+
+		case "env":
+			var e envRequest
+			if err := ssh.Unmarshal(req.Payload, &e); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			env = append(env, e.Name+"="+e.Value)
+			req.Reply(true, nil)
+
+		case "window-change":
+			var w windowChangeRequest
+			if err := ssh.Unmarshal(req.Payload, &w); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			if program != nil {
+				program.Send(tea.WindowSizeMsg{Width: int(w.Columns), Height: int(w.Rows)})
+			} else if pty != nil {
+				pty.Columns, pty.Rows = w.Columns, w.Rows
+			}
+			req.Reply(true, nil)
+
+		case "shell":
+			if program != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			if pty == nil {
+				req.Reply(true, nil)
+				fmt.Fprintf(channel.Stderr(), "bubblebook needs a terminal, connect with ssh -t\r\n")
+				channel.SendRequest("exit-status", false, ssh.Marshal(exitStatus{1}))
+				return
+			}
+			req.Reply(true, nil)
+
+			program = newRemoteProgram(s.model, channel, channel, append(env, "TERM="+pty.Term))
+			go func() {
+				status := uint32(0)
+				if _, err := program.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+					status = 1
+				}
+				channel.SendRequest("exit-status", false, ssh.Marshal(exitStatus{status}))
+				channel.Close()
+			}()
+			program.Send(tea.WindowSizeMsg{Width: int(pty.Columns), Height: int(pty.Rows)})
+
+		default:
+			req.Reply(false, nil)
+		}
+	}
+
+	// The client went away or the program ended
+	if program != nil {
+		program.Kill()
+	}
+}
+
+// checkListener refuses listeners that other machines can connect to when
+// clients are not authenticated.
+func (s *SSHServer) checkListener(l net.Listener) error {
+	if s.config.NoClientAuth && !isLoopback(l.Addr()) {
+		return errNoSSHAuth
+	}
+	return nil
+}
+
+// isLoopback reports whether only this machine can connect to addr.
+func isLoopback(addr net.Addr) bool {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		return addr.IP.IsLoopback()
+	case *net.UnixAddr:
+		return true
+	}
+	return false
+}
+
+// loadHostKey reads the host key from path, creating it when it does not
+// exist. An empty path gives a new key that is not saved.
+func loadHostKey(path string) (ssh.Signer, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return ssh.ParsePrivateKey(data)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		block, err := ssh.MarshalPrivateKey(key, "bubblebook host key")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return ssh.NewSignerFromKey(key)
+}
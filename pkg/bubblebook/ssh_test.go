@@ -0,0 +1,267 @@
+package bubblebook
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// greeting is a story that shows a fixed line.
+type greeting struct{}
+
+func (greeting) Init() tea.Cmd                       { return nil }
+func (greeting) Update(tea.Msg) (tea.Model, tea.Cmd) { return greeting{}, nil }
+func (greeting) View() string                        { return "Hello over SSH" }
+
+// withStories registers only the given stories for the rest of the test.
+func withStories(t *testing.T, entries ...models.ComponentEntry) {
+	t.Helper()
+	saved := components
+	components = entries
+	t.Cleanup(func() {
+		components = saved
+	})
+}
+
+// serveSSH serves the book on a loopback listener until the test ends.
+func serveSSH(t *testing.T, server *SSHServer) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	return l.Addr().String()
+}
+
+// dialSSH connects to addr with the given authentication methods.
+func dialSSH(addr string, auth ...ssh.AuthMethod) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "dev",
+		Auth:            auth,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+// screenOutput collects what a session writes.
+type screenOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *screenOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+// mark returns how much has been written so far.
+func (o *screenOutput) mark() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Len()
+}
+
+// since returns the text written after a mark, without escape sequences.
+func (o *screenOutput) since(mark int) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return ansi.Strip(string(o.buf.Bytes()[mark:]))
+}
+
+// waitFor waits until the text, without escape sequences, holds want.
+func (o *screenOutput) waitFor(t *testing.T, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		o.mu.Lock()
+		text := ansi.Strip(o.buf.String())
+		o.mu.Unlock()
+		if strings.Contains(text, want) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("the session never showed %q", want)
+}
+
+func TestSSHSession(t *testing.T) {
+	withStories(t, models.ComponentEntry{
+		Name:    "Greeting",
+		Factory: func() tea.Model { return greeting{} },
+	})
+
+	server, err := NewSSHServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := dialSSH(serveSSH(t, server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	var output screenOutput
+	session.Stdout = &output
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := session.RequestPty("xterm-256color", 30, 100, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	output.waitFor(t, "Greeting")
+	output.waitFor(t, "Hello over SSH")
+
+	// Quitting the book ends the session
+	io.WriteString(stdin, "q")
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("the session ended with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session did not end after quitting")
+	}
+}
+
+func TestSSHSessionWithoutPty(t *testing.T) {
+	withStories(t)
+
+	server, err := NewSSHServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := dialSSH(serveSSH(t, server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	var exit *ssh.ExitError
+	if err := session.Wait(); !errors.As(err, &exit) || exit.ExitStatus() != 1 {
+		t.Errorf("the session ended with %v, want exit status 1", err)
+	}
+	if !strings.Contains(stderr.String(), "ssh -t") {
+		t.Errorf("stderr = %q, want a hint to request a terminal", stderr.String())
+	}
+}
+
+func TestSSHPasswordAuth(t *testing.T) {
+	withStories(t)
+
+	server, err := NewSSHServer(WithPasswordAuth(func(user, password string) bool {
+		return user == "dev" && password == "secret"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handshakeErrs := make(chan error, 1)
+	server.OnError = func(err error) {
+		handshakeErrs <- err
+	}
+	addr := serveSSH(t, server)
+
+	if client, err := dialSSH(addr, ssh.Password("wrong")); err == nil {
+		client.Close()
+		t.Fatal("a wrong password was accepted")
+	}
+	select {
+	case err := <-handshakeErrs:
+		if !strings.Contains(err.Error(), "handshake") {
+			t.Errorf("OnError got %v, want a handshake error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("OnError was not called for the rejected client")
+	}
+
+	client, err := dialSSH(addr, ssh.Password("secret"))
+	if err != nil {
+		t.Fatalf("the right password was rejected: %v", err)
+	}
+	client.Close()
+}
+
+func TestSSHWithoutAuthNeedsLoopback(t *testing.T) {
+	withStories(t)
+
+	server, err := NewSSHServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Serve(l); !errors.Is(err, errNoSSHAuth) {
+		t.Errorf("Serve on %s = %v, want %v", l.Addr(), err, errNoSSHAuth)
+	}
+
+	authenticated, err := NewSSHServer(WithPasswordAuth(func(string, string) bool { return false }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authenticated.checkListener(l); err != nil {
+		t.Errorf("a server with authentication refused %s: %v", l.Addr(), err)
+	}
+	l.Close()
+}
+
+func TestSSHDisablesFiles(t *testing.T) {
+	server, err := NewSSHServer(WithHistoryLimit(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !server.model.DisableFiles {
+		t.Error("SSH sessions can save files in the server's working directory")
+	}
+	if server.model.HistoryLimit != 10 {
+		t.Errorf("HistoryLimit = %d, want the option's 10", server.model.HistoryLimit)
+	}
+}
@@ -0,0 +1,162 @@
+package bubblebook
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/headless"
+)
+
+// SweepSizes renders a fresh instance of a registered component at every
+// size of a grid and reports the sizes at which it panics, draws outside
+// the area it was given or draws nothing.
+func SweepSizes(name string, opts headless.SweepOptions) ([]headless.SizeResult, error) {
+	entry, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	defer forceColor()()
+	return headless.Sweep(entry.Factory, opts), nil
+}
+
+// runSweep handles `sweep <story> [--width min-max] [--height min-max]
+// [--step WxH]`. It prints a grid of the results and the sizes from which
+// the story works, and fails when it breaks at any size.
+func runSweep(args []string, o options) error {
+	def := headless.DefaultSweepOptions()
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	widths := fs.String("width", fmt.Sprintf("%d-%d", def.MinWidth, def.MaxWidth), "range of widths, as MIN-MAX")
+	heights := fs.String("height", fmt.Sprintf("%d-%d", def.MinHeight, def.MaxHeight), "range of heights, as MIN-MAX")
+	step := fs.String("step", fmt.Sprintf("%dx%d", def.WidthStep, def.HeightStep), "distance between sizes, as WIDTHxHEIGHT")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError{errors.New("sweep needs the name of one story")}
+	}
+
+	var opts headless.SweepOptions
+	var err error
+	if opts.MinWidth, opts.MaxWidth, err = parseRange(*widths); err != nil {
+		return usageError{err}
+	}
+	if opts.MinHeight, opts.MaxHeight, err = parseRange(*heights); err != nil {
+		return usageError{err}
+	}
+	if opts.WidthStep, opts.HeightStep, err = parseSize(*step); err != nil {
+		return usageError{err}
+	}
+
+	results, err := SweepSizes(fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	printSweep(results)
+
+	failed := 0
+	for _, r := range results {
+		if !r.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s breaks at %d of %d sizes", fs.Arg(0), failed, len(results))
+	}
+	return nil
+}
+
+// printSweep prints the results of a sweep as a grid with a row per height
+// and a column per width, followed by a summary.
+func printSweep(results []headless.SizeResult) {
+	var widths []int
+	for _, r := range results {
+		if len(widths) > 0 && r.Width == widths[0] {
+			break
+		}
+		widths = append(widths, r.Width)
+	}
+
+	fmt.Printf("%6s", "")
+	for _, w := range widths {
+		fmt.Printf("%4d", w)
+	}
+	fmt.Println()
+	for i, r := range results {
+		if i%len(widths) == 0 {
+			fmt.Printf("%6d", r.Height)
+		}
+		fmt.Printf("%4s", sweepMark(r))
+		if i%len(widths) == len(widths)-1 {
+			fmt.Println()
+		}
+	}
+	fmt.Println("\n. works  P panics  O overflows  E empty")
+
+	var panicked, overflowed, empty []headless.SizeResult
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			panicked = append(panicked, r)
+		case r.Overflow:
+			overflowed = append(overflowed, r)
+		case r.Empty:
+			empty = append(empty, r)
+		}
+	}
+	if len(panicked) > 0 {
+		r := panicked[0]
+		fmt.Printf("Panics at %d of %d sizes, first at %dx%d: %v\n", len(panicked), len(results), r.Width, r.Height, r.Err)
+	}
+	if len(overflowed) > 0 {
+		r := overflowed[0]
+		fmt.Printf("Overflows at %d of %d sizes, first at %dx%d\n", len(overflowed), len(results), r.Width, r.Height)
+	}
+	if len(empty) > 0 {
+		r := empty[0]
+		fmt.Printf("Empty at %d of %d sizes, first at %dx%d\n", len(empty), len(results), r.Width, r.Height)
+	}
+
+	breakpoints := headless.Breakpoints(results)
+	if len(breakpoints) == 0 {
+		fmt.Println("Breaks even at the largest size")
+		return
+	}
+	sizes := make([]string, len(breakpoints))
+	for i, b := range breakpoints {
+		sizes[i] = fmt.Sprintf("%dx%d", b.Width, b.Height)
+	}
+	fmt.Printf("Works at every size from %s up\n", strings.Join(sizes, " or "))
+}
+
+// sweepMark is the grid symbol for one result. A panic hides an overflow,
+// which hides an empty view.
+func sweepMark(r headless.SizeResult) string {
+	switch {
+	case r.Err != nil:
+		return "P"
+	case r.Overflow:
+		return "O"
+	case r.Empty:
+		return "E"
+	}
+	return "."
+}
+
+// parseRange parses a range written as MIN-MAX, or a single number.
+func parseRange(s string) (lo, hi int, err error) {
+	l, h, ok := strings.Cut(s, "-")
+	if !ok {
+		h = l
+	}
+	lo, err = strconv.Atoi(l)
+	if err == nil {
+		hi, err = strconv.Atoi(h)
+	}
+	if err != nil || lo <= 0 || hi < lo {
+		return 0, 0, fmt.Errorf("invalid range %q, expected MIN-MAX such as 20-200", s)
+	}
+	return lo, hi, nil
+}
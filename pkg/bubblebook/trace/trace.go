@@ -0,0 +1,253 @@
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Source tells where a traced message came from
+type Source string
+
+const (
+	// SourceInput is a message sent to the component by bubblebook, such as
+	// a key press or a resize
+	SourceInput Source = "input"
+	// SourceCommand is the result of a command the component returned
+	SourceCommand Source = "command"
+)
+
+// Entry is a single message that reached the component
+type Entry struct {
+	// Offset is the time since the trace started
+	Offset time.Duration `json:"offset"`
+	Source Source        `json:"source"`
+	// Type is the package-qualified name of the message type
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+	// Error is set instead of Data when the message could not be encoded,
+	// which makes the trace impossible to replay
+	Error string `json:"error,omitempty"`
+	// Internal marks a message whose data cannot be saved, because its type
+	// or all of its fields are unexported. It is left out of replays.
+	Internal bool `json:"internal,omitempty"`
+
+	// Msg is the decoded message
+	Msg tea.Msg `json:"-"`
+}
+
+// Trace is a recording of every message a component received, in order,
+// starting from a fresh instance of the component
+type Trace struct {
+	Story    string    `json:"story"`
+	Recorded time.Time `json:"recorded"`
+	Entries  []Entry   `json:"messages"`
+
+	// Skipped counts the internal messages Load could not decode, which
+	// Messages leaves out
+	Skipped int `json:"-"`
+}
+
+// New starts an empty trace for a story
+func New(story string, start time.Time) *Trace {
+	return &Trace{
+		Story:    story,
+		Recorded: start,
+	}
+}
+
+// Add appends a message received at time t. Messages are encoded with
+// encoding/json, so only their exported fields survive a save. A message
+// that cannot be encoded is still added, marked with the error, so the
+// saved trace is known to be incomplete.
+//
+// Messages of unexported types, or with only unexported fields, such as the
+// blink ticks of bubbles' cursor, are internal to the package that sends
+// them: their data cannot be saved, and unexported types cannot be
+// registered. They are added without data, and left out when the trace is
+// replayed.
+func (t *Trace) Add(at time.Time, source Source, msg tea.Msg) error {
+	entry := Entry{
+		Offset: at.Sub(t.Recorded),
+		Source: source,
+		Type:   TypeName(msg),
+		Msg:    msg,
+	}
+	if internal(msg) {
+		entry.Internal = true
+		t.Entries = append(t.Entries, entry)
+		return nil
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		err = fmt.Errorf("encoding %s: %w", entry.Type, err)
+		entry.Error = err.Error()
+	}
+	entry.Data = data
+	t.Entries = append(t.Entries, entry)
+	return err
+}
+
+// Complete returns whether every message of the trace could be encoded
+func (t *Trace) Complete() bool {
+	for _, entry := range t.Entries {
+		if entry.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Messages returns the recorded messages in the order they were received,
+// apart from internal ones that were skipped when loading
+func (t *Trace) Messages() []tea.Msg {
+	msgs := make([]tea.Msg, 0, len(t.Entries))
+	for _, entry := range t.Entries {
+		if entry.Msg != nil {
+			msgs = append(msgs, entry.Msg)
+		}
+	}
+	return msgs
+}
+
+// Save writes the trace to a JSON file
+func (t *Trace) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads a trace from a JSON file and decodes its messages. Every
+// exported message type in the trace must have been registered with
+// RegisterType, apart from Bubble Tea's own input messages, and traces with
+// messages that could not be encoded are refused. Internal messages are
+// skipped, unless their type was registered.
+func Load(path string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("reading trace %s: %w", path, err)
+	}
+
+	unknown := make(map[string]bool)
+	for i := range t.Entries {
+		entry := &t.Entries[i]
+		if entry.Error != "" {
+			return nil, fmt.Errorf("trace %s is incomplete, message %d was not saved: %s", path, i, entry.Error)
+		}
+
+		typ, ok := lookupType(entry.Type)
+		if !ok && entry.Internal {
+			t.Skipped++
+			continue
+		}
+		if !ok {
+			unknown[entry.Type] = true
+			continue
+		}
+
+		ptr := reflect.New(typ)
+		if err := json.Unmarshal(entry.Data, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("decoding message %d (%s): %w", i, entry.Type, err)
+		}
+		entry.Msg = ptr.Elem().Interface()
+	}
+
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+		for name := range unknown {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("trace uses unregistered message types: %s", strings.Join(names, ", "))
+	}
+
+	return &t, nil
+}
+
+var (
+	typesMu sync.RWMutex
+	types   = make(map[string]reflect.Type)
+)
+
+func init() {
+	RegisterType(
+		tea.KeyMsg{},
+		tea.MouseMsg{},
+		tea.WindowSizeMsg{},
+		tea.FocusMsg{},
+		tea.BlurMsg{},
+	)
+}
+
+// RegisterType makes the types of the given example messages known to Load
+func RegisterType(msgs ...tea.Msg) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	for _, msg := range msgs {
+		types[TypeName(msg)] = reflect.TypeOf(msg)
+	}
+}
+
+// lookupType finds a registered message type by name
+func lookupType(name string) (reflect.Type, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	typ, ok := types[name]
+	return typ, ok
+}
+
+// TypeName returns the package-qualified name of a message's type, such as
+// "github.com/charmbracelet/bubbletea.KeyMsg"
+func TypeName(msg tea.Msg) string {
+	typ := reflect.TypeOf(msg)
+	if typ == nil {
+		return "<nil>"
+	}
+	if typ.Name() == "" || typ.PkgPath() == "" {
+		return typ.String()
+	}
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+// internal reports whether a message's data cannot be saved, because its
+// type is unexported or it is a struct whose fields are all unexported.
+// Registered types are never internal.
+func internal(msg tea.Msg) bool {
+	typ := reflect.TypeOf(msg)
+	if typ == nil {
+		return false
+	}
+	if _, ok := lookupType(TypeName(msg)); ok {
+		return false
+	}
+	if typ.Name() != "" && !token.IsExported(typ.Name()) {
+		return true
+	}
+	if typ.Kind() != reflect.Struct || typ.NumField() == 0 {
+		return false
+	}
+	for i := range typ.NumField() {
+		if typ.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchedMsg is a message a component receives from one of its commands.
+type FetchedMsg struct {
+	Items []string
+}
+
+// blinkMsg stands for the unexported tick messages of other packages.
+type blinkMsg struct{}
+
+// TickMsg stands for exported messages that only have unexported fields,
+// such as bubbles' cursor.BlinkMsg.
+type TickMsg struct {
+	id int
+}
+
+// StreamMsg cannot be encoded as JSON.
+type StreamMsg struct {
+	C chan int
+}
+
+func init() {
+	RegisterType(FetchedMsg{})
+}
+
+func TestSaveLoad(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tr := New("List", start)
+	msgs := []tea.Msg{
+		tea.WindowSizeMsg{Width: 80, Height: 24},
+		blinkMsg{},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")},
+		TickMsg{id: 1},
+		FetchedMsg{Items: []string{"a", "b"}},
+	}
+	for i, msg := range msgs {
+		if err := tr.Add(start.Add(time.Duration(i)*time.Second), SourceInput, msg); err != nil {
+			t.Fatalf("Add(%T): %v", msg, err)
+		}
+	}
+	if !tr.Complete() {
+		t.Error("a trace of encodable messages is incomplete")
+	}
+
+	path := filepath.Join(t.TempDir(), "list.trace.json")
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []tea.Msg{msgs[0], msgs[2], msgs[4]}
+	if got := loaded.Messages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Messages() = %#v, want %#v", got, want)
+	}
+	if loaded.Skipped != 2 {
+		t.Errorf("Skipped = %d, want the blink and tick messages", loaded.Skipped)
+	}
+	if loaded.Story != "List" || !loaded.Recorded.Equal(start) || loaded.Entries[4].Offset != 4*time.Second {
+		t.Errorf("loaded trace = %+v", loaded)
+	}
+}
+
+func TestLoadRefusesIncompleteTraces(t *testing.T) {
+	start := time.Now()
+	tr := New("Stream", start)
+	if err := tr.Add(start, SourceCommand, StreamMsg{C: make(chan int)}); err == nil {
+		t.Fatal("Add encoded a channel")
+	}
+	if tr.Complete() {
+		t.Error("a trace with an unencodable message is complete")
+	}
+
+	path := filepath.Join(t.TempDir(), "stream.trace.json")
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("Load() = %v, want the trace refused as incomplete", err)
+	}
+}
+
+func TestLoadRefusesUnregisteredTypes(t *testing.T) {
+	type UnknownMsg struct{ N int }
+
+	start := time.Now()
+	tr := New("Unknown", start)
+	tr.Add(start, SourceCommand, UnknownMsg{N: 1})
+
+	path := filepath.Join(t.TempDir(), "unknown.trace.json")
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "UnknownMsg") {
+		t.Errorf("Load() = %v, want UnknownMsg reported as unregistered", err)
+	}
+}
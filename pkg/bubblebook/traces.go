@@ -0,0 +1,46 @@
+package bubblebook
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/console"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/headless"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/trace"
+)
+
+// RegisterMsgType makes custom message types known to trace replay. Pass an
+// example value of each type a component receives; Bubble Tea's own input
+// messages are always known.
+func RegisterMsgType(msgs ...tea.Msg) {
+	trace.RegisterType(msgs...)
+}
+
+// RegisterConsoleMsg adds a command to the message console that sends a
+// custom message, such as an error that is hard to reach with the keyboard,
+// to whatever story is active. Typing "name args" calls parse with the args
+// and sends the message it returns. Commands of one story are added with
+// WithConsoleMsg instead, and take precedence over these. It panics when
+// name is empty, contains spaces or is one of the built-in commands.
+func RegisterConsoleMsg(name string, parse func(args string) (tea.Msg, error)) {
+	if err := console.Register(name, parse); err != nil {
+		panic(fmt.Sprintf("bubblebook: %v", err))
+	}
+}
+
+// ReplayTrace loads a trace file, feeds its messages to a fresh instance of
+// the component it was recorded for, and returns the resulting view.
+func ReplayTrace(path string) (string, error) {
+	t, err := trace.Load(path)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := lookup(t.Story)
+	if err != nil {
+		return "", err
+	}
+
+	defer forceColor()()
+	return headless.Replay(entry.Factory(), t.Messages())
+}
@@ -0,0 +1,94 @@
+package bubblebook
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/trace"
+)
+
+// ResetMsg is sent to a counter by one of its commands.
+type ResetMsg struct {
+	To int
+}
+
+// counter counts the keys it is sent.
+type counter struct {
+	n int
+}
+
+func (c counter) Init() tea.Cmd { return nil }
+
+func (c counter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		c.n++
+	case ResetMsg:
+		c.n = msg.To
+	}
+	return c, nil
+}
+
+func (c counter) View() string { return "Count: " + strconv.Itoa(c.n) }
+
+func TestReplayTrace(t *testing.T) {
+	withStories(t, models.ComponentEntry{
+		Name:    "Counter",
+		Factory: func() tea.Model { return counter{} },
+	})
+	RegisterMsgType(ResetMsg{})
+
+	start := time.Now()
+	tr := trace.New("Counter", start)
+	for _, msg := range []tea.Msg{
+		tea.KeyMsg{Type: tea.KeyUp},
+		ResetMsg{To: 10},
+		tea.KeyMsg{Type: tea.KeyUp},
+		tea.KeyMsg{Type: tea.KeyUp},
+	} {
+		if err := tr.Add(start, trace.SourceInput, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "counter.trace.json")
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := ReplayTrace(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view != "Count: 12" {
+		t.Errorf("ReplayTrace() = %q, want \"Count: 12\"", view)
+	}
+}
+
+func TestWithConsoleMsg(t *testing.T) {
+	withStories(t)
+	Register("Counter", func() tea.Model { return counter{} },
+		WithConsoleMsg("reset", func(args string) (tea.Msg, error) {
+			n, err := strconv.Atoi(args)
+			return ResetMsg{To: n}, err
+		}),
+	)
+
+	parse, ok := components[0].ConsoleMsgs["reset"]
+	if !ok {
+		t.Fatal("the story has no reset command")
+	}
+	if msg, err := parse("3"); err != nil || msg != (ResetMsg{To: 3}) {
+		t.Errorf("reset 3 = %v, %v, want %v", msg, err, ResetMsg{To: 3})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a story command named after a built-in one was accepted")
+		}
+	}()
+	WithConsoleMsg("key", nil)
+}
@@ -0,0 +1,256 @@
+package bubblebook
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+)
+
+// watchStateEnv names the file a book started by `watch` keeps its UI
+// state in across restarts.
+const watchStateEnv = "BUBBLEBOOK_WATCH_STATE"
+
+// stopTimeout is how long a book gets to save its state and exit before it
+// is killed.
+const stopTimeout = 5 * time.Second
+
+// runWatch handles `watch [package] [--interval d] [--no-state]
+// [--clear-state]`. It builds and runs the book, and rebuilds and restarts
+// it whenever a Go file in the module changes. Build errors are shown in the
+// terminal until the next change.
+func runWatch(args []string, o options) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 500*time.Millisecond, "how often to check for changed files")
+	noState := fs.Bool("no-state", false, "neither restore nor save the UI state between runs")
+	clearState := fs.Bool("clear-state", false, "delete the saved UI state before starting")
+	if err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	// The saved state is cleared once, not on every restart
+	var bookArgs []string
+	if *noState {
+		bookArgs = append(bookArgs, "--no-state")
+	} else if *clearState {
+		clearSession()
+	}
+
+	pkg := "."
+	if fs.NArg() > 0 {
+		pkg = fs.Arg(0)
+	}
+
+	root, err := moduleRoot(pkg)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "bubblebook-watch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	binary := filepath.Join(dir, "book")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	state := filepath.Join(dir, "state.json")
+	watcher := newSourceWatcher(root, *interval)
+
+	for {
+		var problem, output string
+		if out, err := exec.Command("go", "build", "-o", binary, pkg).CombinedOutput(); err != nil {
+			problem, output = "Build failed", string(out)
+		} else {
+			restart, err := runBook(binary, bookArgs, state, watcher)
+			if !restart {
+				return err
+			}
+			if err == nil {
+				continue
+			}
+			problem, output = "Book exited unexpectedly", err.Error()
+		}
+
+		changed, err := showProblem(problem, output, watcher)
+		if err != nil || !changed {
+			return err
+		}
+	}
+}
+
+// runBook runs a built book until it exits or a source file changes. It
+// reports whether the book should be rebuilt, together with the error it
+// exited with when it did so on its own.
+func runBook(binary string, args []string, state string, watcher *sourceWatcher) (restart bool, err error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(binary, args...)
+	cmd.Env = append(os.Environ(), watchStateEnv+"="+state)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	stop := make(chan struct{})
+	changed := make(chan bool, 1)
+	go func() {
+		changed <- watcher.wait(stop)
+	}()
+
+	select {
+	case err := <-exited:
+		close(stop)
+		if err == nil {
+			// The book was quit
+			return false, nil
+		}
+		if out := strings.TrimSpace(stderr.String()); out != "" {
+			err = fmt.Errorf("%w\n\n%s", err, out)
+		}
+		return true, err
+
+	case <-changed:
+		// Give the book a chance to save its state before restarting it
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			cmd.Process.Kill()
+		}
+		select {
+		case <-exited:
+		case <-time.After(stopTimeout):
+			cmd.Process.Kill()
+			<-exited
+		}
+		return true, nil
+	}
+}
+
+// showProblem shows a problem with the book until a source file changes.
+// It reports false when the user quit instead.
+func showProblem(title, output string, watcher *sourceWatcher) (bool, error) {
+	program := tea.NewProgram(models.NewProblemModel(title, output), tea.WithAltScreen())
+
+	var changed atomic.Bool
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		if watcher.wait(stop) {
+			changed.Store(true)
+			program.Quit()
+		}
+	}()
+
+	if _, err := program.Run(); err != nil {
+		return false, err
+	}
+	return changed.Load(), nil
+}
+
+// moduleRoot returns the root directory of the module a package belongs to.
+func moduleRoot(pkg string) (string, error) {
+	out, err := exec.Command("go", "list", "-f", "{{.Module.Dir}}", pkg).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("finding the module of %s: %s", pkg, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", err
+	}
+	root := strings.TrimSpace(string(out))
+	if root == "" {
+		return "", fmt.Errorf("%s is not part of a module", pkg)
+	}
+	return root, nil
+}
+
+// sourceWatcher polls the Go sources of a module for changes.
+type sourceWatcher struct {
+	root     string
+	interval time.Duration
+	files    map[string]time.Time
+}
+
+// newSourceWatcher creates a watcher that treats the current files as
+// unchanged.
+func newSourceWatcher(root string, interval time.Duration) *sourceWatcher {
+	w := &sourceWatcher{root: root, interval: interval}
+	w.files = w.scan()
+	return w
+}
+
+// wait blocks until a source file is added, changed or removed, and
+// reports whether one was. It returns false once stop is closed.
+func (w *sourceWatcher) wait(stop <-chan struct{}) bool {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-ticker.C:
+			files := w.scan()
+			if !sameFiles(files, w.files) {
+				w.files = files
+				return true
+			}
+		}
+	}
+}
+
+// scan returns the modification times of the module's Go files, go.mod and
+// go.sum. Hidden directories, vendor and testdata are skipped.
+func (w *sourceWatcher) scan() map[string]time.Time {
+	files := make(map[string]time.Time)
+	filepath.WalkDir(w.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != w.root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.sum" {
+			if info, err := d.Info(); err == nil {
+				files[path] = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// sameFiles compares two scans.
+func sameFiles(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, modified := range a {
+		if other, ok := b[path]; !ok || !other.Equal(modified) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,278 @@
+package bubblebook
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+	"golang.org/x/net/websocket"
+)
+
+// defaultWebAddr is where `serve` listens when no address is given. The
+// port is the one Storybook uses.
+const defaultWebAddr = "localhost:6006"
+
+// webEnv is the environment of a browser session. The page's terminal
+// emulator supports every colour.
+var webEnv = []string{"TERM=xterm-256color", "COLORTERM=truecolor"}
+
+// errNoWebAuth is returned when the book is asked to serve browsers on an
+// address other machines can reach.
+var errNoWebAuth = errors.New("bubblebook: browsers are not authenticated, so the book only listens on loopback addresses; give it a token with WithWebToken, or mount WebHandler behind your own authentication, to serve other machines")
+
+// webOptions configure the server for browsers.
+type webOptions struct {
+	token string
+}
+
+// WithWebToken makes the server for browsers let in only pages opened with
+// the token, as in http://host:6006/?token=secret. Books that are given a
+// token can be served on addresses other machines can reach.
+func WithWebToken(token string) Option {
+	return func(o *options) {
+		o.web.token = token
+	}
+}
+
+// webMessage is sent by the page over the WebSocket: either input typed
+// into the terminal, or its new size.
+type webMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Cols int    `json:"cols,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+}
+
+// WebHandler returns an HTTP handler that serves the book to browsers. It
+// serves a page with a terminal emulator at its root, and every page that is
+// opened gets its own TUI over a WebSocket. It takes the same options as
+// Start.
+//
+// WebSocket connections from pages served by other sites are refused, and
+// sessions cannot record, trace or save profiles, since the files would
+// land in the server's working directory. The page loads xterm.js from
+// cdn.jsdelivr.net, so browsers need to reach it.
+func WebHandler(opts ...Option) http.Handler {
+	o := options{model: models.DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newWebHandler(o)
+}
+
+// ServeWeb serves the book to browsers on addr, such as "localhost:6006",
+// until it fails. Without WithWebToken browsers are not authenticated, so
+// addr has to be a loopback address.
+func ServeWeb(addr string, opts ...Option) error {
+	o := options{model: models.DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l, err := listenWeb(addr, o)
+	if err != nil {
+		return err
+	}
+	return http.Serve(l, newWebHandler(o))
+}
+
+// listenWeb listens on addr for browsers, refusing addresses that other
+// machines can connect to unless browsers need a token.
+func listenWeb(addr string, o options) (net.Listener, error) {
+	if o.web.token != "" {
+		return net.Listen("tcp", addr)
+	}
+	return listenLoopback(addr)
+}
+
+// listenLoopback listens on addr, refusing addresses that other machines
+// can connect to.
+func listenLoopback(addr string) (net.Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if !isLoopback(l.Addr()) {
+		l.Close()
+		return nil, errNoWebAuth
+	}
+	return l, nil
+}
+
+// newWebHandler creates the handler from collected options.
+func newWebHandler(o options) http.Handler {
+	model := o.model
+	model.DisableFiles = true
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, webPage)
+	})
+	mux.Handle("GET /ws", websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if err := checkSameOrigin(config, r); err != nil {
+				return err
+			}
+			return checkToken(o.web.token, r)
+		},
+		Handler: func(ws *websocket.Conn) {
+			serveWebSession(ws, model)
+		},
+	})
+	return mux
+}
+
+// checkSameOrigin refuses WebSocket connections opened by pages of other
+// sites, which could otherwise drive the book from the user's browser.
+func checkSameOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	if err != nil || origin.Host != r.Host {
+		return errors.New("cross-origin WebSocket connection refused")
+	}
+	config.Origin = origin
+	return nil
+}
+
+// newWebToken generates a token that cannot be guessed.
+func newWebToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkToken refuses WebSocket connections without the book's token, when
+// it has one. The page passes on the token it was opened with.
+func checkToken(token string, r *http.Request) error {
+	if token == "" {
+		return nil
+	}
+	given := r.URL.Query().Get("token")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		return errors.New("WebSocket connection without the book's token refused")
+	}
+	return nil
+}
+
+// serveWebSession runs a TUI for one page until it is quit or the page is
+// closed.
+func serveWebSession(ws *websocket.Conn, config models.Config) {
+	defer ws.Close()
+
+	input, typed := io.Pipe()
+	program := newRemoteProgram(config, input, &webOutput{ws: ws}, webEnv)
+
+	go func() {
+		defer typed.Close()
+		for {
+			var msg webMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				// The page was closed
+				program.Kill()
+				return
+			}
+
+			switch msg.Type {
+			case "input":
+				if _, err := io.WriteString(typed, msg.Data); err != nil {
+					return
+				}
+			case "resize":
+				if msg.Cols > 0 && msg.Rows > 0 {
+					program.Send(tea.WindowSizeMsg{Width: msg.Cols, Height: msg.Rows})
+				}
+			}
+		}
+	}()
+
+	// The page is told why a session that failed ended, as the socket is
+	// closed right after
+	if _, err := program.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		websocket.Message.Send(ws, []byte(fmt.Sprintf("\r\n\x1b[31mbubblebook: %v\x1b[0m\r\n", err)))
+	}
+}
+
+// webOutput sends what a program writes to the page as binary messages.
+type webOutput struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+func (w *webOutput) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := websocket.Message.Send(w.ws, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// webPage runs xterm.js and connects it to the WebSocket next to it. The
+// scripts come from a CDN rather than the binary, which keeps books small.
+const webPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Bubblebook</title>
+<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/css/xterm.css">
+<style>
+html, body { margin: 0; height: 100%; background: #000; }
+#terminal { height: 100%; }
+</style>
+</head>
+<body>
+<div id="terminal"></div>
+<script src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js"></script>
+<script src="https://cdn.jsdelivr.net/npm/@xterm/addon-fit@0.10.0/lib/addon-fit.js"></script>
+<script>
+if (typeof Terminal === "undefined") {
+  document.body.style.color = "#ccc";
+  document.body.textContent = "The terminal emulator could not be loaded from cdn.jsdelivr.net.";
+  throw new Error("xterm.js is not available");
+}
+
+const term = new Terminal({ fontFamily: "Menlo, Consolas, 'DejaVu Sans Mono', monospace" });
+const fit = new FitAddon.FitAddon();
+term.loadAddon(fit);
+term.open(document.getElementById("terminal"));
+fit.fit();
+
+const url = new URL("ws", location.href);
+url.protocol = url.protocol.replace("http", "ws");
+url.search = location.search;
+const ws = new WebSocket(url);
+ws.binaryType = "arraybuffer";
+
+const send = (msg) => {
+  if (ws.readyState === WebSocket.OPEN) {
+    ws.send(JSON.stringify(msg));
+  }
+};
+const sendSize = () => send({ type: "resize", cols: term.cols, rows: term.rows });
+
+ws.onopen = () => {
+  sendSize();
+  term.focus();
+};
+ws.onmessage = (e) => term.write(new Uint8Array(e.data));
+ws.onclose = () => term.write("\r\n\x1b[2mThe session ended. Reload the page to start a new one.\x1b[0m\r\n");
+
+term.onData((data) => send({ type: "input", data }));
+term.onResize(sendSize);
+window.addEventListener("resize", () => fit.fit());
+</script>
+</body>
+</html>
+`
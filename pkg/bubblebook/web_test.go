@@ -0,0 +1,178 @@
+package bubblebook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/models"
+	"golang.org/x/net/websocket"
+)
+
+// sizeReporter is a story that shows the width it was given.
+type sizeReporter struct {
+	width int
+}
+
+func (m sizeReporter) Init() tea.Cmd { return nil }
+
+func (m sizeReporter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+func (m sizeReporter) View() string {
+	return "given " + strconv.Itoa(m.width) + " columns"
+}
+
+// serveWeb serves the book to browsers until the test ends.
+func serveWeb(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(WebHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dialWeb opens the book's WebSocket as a page of origin would.
+func dialWeb(server *httptest.Server, origin string) (*websocket.Conn, error) {
+	return websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", origin)
+}
+
+func TestWebPage(t *testing.T) {
+	withStories(t)
+	server := serveWeb(t)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET / = %s", resp.Status)
+	}
+	if !strings.Contains(string(body), "new WebSocket(url)") {
+		t.Error("the page does not connect to the book")
+	}
+}
+
+func TestWebRefusesOtherOrigins(t *testing.T) {
+	withStories(t)
+	server := serveWeb(t)
+
+	if ws, err := dialWeb(server, "http://evil.example"); err == nil {
+		ws.Close()
+		t.Error("a page of another site was let in")
+	}
+	ws, err := dialWeb(server, server.URL)
+	if err != nil {
+		t.Fatalf("a page of the book was refused: %v", err)
+	}
+	ws.Close()
+}
+
+func TestWebResize(t *testing.T) {
+	withStories(t, models.ComponentEntry{
+		Name:    "Size",
+		Factory: func() tea.Model { return sizeReporter{} },
+	})
+	server := serveWeb(t)
+
+	ws, err := dialWeb(server, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var output screenOutput
+	go func() {
+		for {
+			var data []byte
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				return
+			}
+			output.Write(data)
+		}
+	}()
+
+	// The story is given part of the terminal, so it is only known to have
+	// been resized when it is given more columns than before
+	given := regexp.MustCompile(`given (\d+) columns`)
+	resize := func(cols, rows, before int) int {
+		t.Helper()
+		mark := output.mark()
+		if err := websocket.JSON.Send(ws, webMessage{Type: "resize", Cols: cols, Rows: rows}); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			for _, match := range given.FindAllStringSubmatch(output.since(mark), -1) {
+				if width, _ := strconv.Atoi(match[1]); width > before {
+					return width
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("the story was not given more than %d columns after resizing to %dx%d", before, cols, rows)
+		return 0
+	}
+
+	narrow := resize(100, 30, 0)
+	resize(160, 30, narrow)
+}
+
+func TestServeWebNeedsLoopback(t *testing.T) {
+	if err := ServeWeb(":0"); !errors.Is(err, errNoWebAuth) {
+		t.Errorf("ServeWeb(\":0\") = %v, want %v", err, errNoWebAuth)
+	}
+}
+
+func TestWebToken(t *testing.T) {
+	withStories(t)
+	server := httptest.NewServer(WebHandler(WithWebToken("secret")))
+	t.Cleanup(server.Close)
+
+	dial := func(query string) error {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, "", server.URL)
+		if err == nil {
+			ws.Close()
+		}
+		return err
+	}
+	if dial("") == nil {
+		t.Error("a page without the token was let in")
+	}
+	if dial("?token=guess") == nil {
+		t.Error("a page with the wrong token was let in")
+	}
+	if err := dial("?token=secret"); err != nil {
+		t.Errorf("a page with the token was refused: %v", err)
+	}
+}
+
+func TestListenWebWithToken(t *testing.T) {
+	o := options{model: models.DefaultConfig()}
+	if _, err := listenWeb(":0", o); !errors.Is(err, errNoWebAuth) {
+		t.Errorf("listenWeb(\":0\") without a token = %v, want %v", err, errNoWebAuth)
+	}
+
+	WithWebToken("secret")(&o)
+	l, err := listenWeb(":0", o)
+	if err != nil {
+		t.Fatalf("listenWeb(\":0\") with a token = %v", err)
+	}
+	l.Close()
+}
@@ -0,0 +1,22 @@
+package wide
+
+// StressText is a string that is hard to measure, with a name saying why
+type StressText struct {
+	Name string
+	Text string
+}
+
+// StressTexts are strings that break layouts which assume one cell per
+// byte or per rune. Stories can use them as sample data, and the preview
+// pastes them into the active story.
+var StressTexts = []StressText{
+	{"CJK", "漢字とかなカナ 한국어"},
+	{"fullwidth", "Ｆｕｌｌｗｉｄｔｈ\u3000ＡＢＣ１２３"},
+	{"combining marks", "e\u0301le\u0300ve Z\u0335\u0321a\u0336\u0327l\u0334go"},
+	{"emoji", "✅ 🚀 📦 🎉"},
+	{"joined emoji", "👩\u200d👩\u200d👧\u200d👦 🧑\u200d💻 🏳\ufe0f\u200d🌈"},
+	{"variation selectors", "\u2764\ufe0f \u2600\ufe0f \u2714\ufe0e \u2714\ufe0f"},
+	{"ambiguous width", "…•→±×°§ ─│╭╮"},
+	{"zero width", "zero\u200bwidth\u200cjoin\u200dmarks\ufeff"},
+	{"right to left", "שלום مرحبا hello"},
+}
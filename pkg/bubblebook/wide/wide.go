@@ -0,0 +1,68 @@
+// Package wide helps test how views cope with characters whose width is not
+// one cell everywhere: East Asian ambiguous-width characters, which CJK
+// terminals draw two cells wide, and wide, combining and joined characters.
+package wide
+
+import (
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/sarkarshuvojit/bubblebook/pkg/bubblebook/screen"
+	"golang.org/x/text/width"
+)
+
+// Ambiguous reports whether a rune has East Asian ambiguous width: one cell
+// in most terminals, but two in terminals set up for CJK text. Box drawing
+// characters, arrows, bullets, "…" and many accented letters are.
+func Ambiguous(r rune) bool {
+	return width.LookupRune(r).Kind() == width.EastAsianAmbiguous
+}
+
+// Widen lays out a view as a terminal with East Asian width rules would
+// draw it, with every ambiguous-width character taking two cells. mark is
+// called with each of those characters, and with the blank cell after it,
+// so they can be highlighted. It returns the laid out cells and how many
+// characters were widened.
+func Widen(view string, mark func(*cellbuf.Cell)) (*cellbuf.Buffer, int) {
+	buf := screen.Parse(view, 0, 0)
+	out := cellbuf.NewBuffer(buf.Width()*2, buf.Height())
+
+	widened := 0
+	for y := 0; y < buf.Height(); y++ {
+		x := 0
+		for col := 0; col < buf.Width(); col++ {
+			c := buf.Cell(col, y)
+			if c == nil {
+				x++
+				continue
+			}
+			if c.Width == 0 {
+				// Placeholders of wide characters belong to the cell before
+				continue
+			}
+			if !ambiguousCell(c) {
+				out.SetCell(x, y, c)
+				x += c.Width
+				continue
+			}
+
+			widened++
+			c = c.Clone()
+			pad := cellbuf.NewCell(' ')
+			pad.Style = c.Style
+			if mark != nil {
+				mark(c)
+				mark(pad)
+			}
+			out.SetCell(x, y, c)
+			out.SetCell(x+1, y, pad)
+			x += 2
+		}
+	}
+	return out, widened
+}
+
+// ambiguousCell reports whether a cell holds a single ambiguous-width
+// character. Characters followed by a variation selector or joined to
+// others are left to the terminal's grapheme handling.
+func ambiguousCell(c *cellbuf.Cell) bool {
+	return c != nil && c.Width == 1 && len(c.Comb) == 0 && Ambiguous(c.Rune)
+}